@@ -0,0 +1,188 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cloudprovider
+
+import (
+	"errors"
+
+	apiv1 "k8s.io/api/core/v1"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// ErrNotImplemented is returned by cloud provider methods that a particular
+// implementation does not support.
+var ErrNotImplemented = errors.New("not implemented")
+
+// ErrAlreadyExist is returned if a method creates an object that already exists.
+var ErrAlreadyExist = errors.New("already exist")
+
+// ErrIllegalConfiguration is returned if a method fails due to an invalid configuration.
+var ErrIllegalConfiguration = errors.New("illegal configuration")
+
+// InstanceStatus represents status of the node instance from cloud provider's perspective.
+type InstanceStatus struct {
+	// State tells if instance is Creating, Running or Deleting.
+	State InstanceState
+	// ErrorInfo is not nil if there is an error condition related to instance
+	// creation, running or deletion.
+	ErrorInfo *InstanceErrorInfo
+}
+
+// InstanceState tells if instance is Creating, Running, Deleting or Unknown.
+type InstanceState int
+
+const (
+	// InstanceRunning means instance has started and is running.
+	InstanceRunning InstanceState = 1
+	// InstanceCreating means instance is being created.
+	InstanceCreating InstanceState = 2
+	// InstanceDeleting means instance is being deleted.
+	InstanceDeleting InstanceState = 3
+)
+
+// InstanceErrorInfo contains information about error conditions on instances.
+type InstanceErrorInfo struct {
+	// ErrorClass tells if the error is related to a node creation or other activities.
+	ErrorClass InstanceErrorClass
+	// ErrorCode is cloud-provider specific error code for the error condition.
+	ErrorCode string
+	// ErrorMessage is a human readable description of the error.
+	ErrorMessage string
+}
+
+// InstanceErrorClass defines class of error conditions on instances.
+type InstanceErrorClass int
+
+const (
+	// OtherErrorClass means the error condition is unknown.
+	OtherErrorClass InstanceErrorClass = 0
+	// OutOfResourcesErrorClass means the error is related to lack of resources available.
+	OutOfResourcesErrorClass InstanceErrorClass = 1
+)
+
+// Instance represents a cloud provider instance, as known to the node group it belongs to.
+type Instance struct {
+	// Id is the provider id of the instance.
+	Id string
+	// Status represents status of the node. This can be nil, if instance status
+	// is not known.
+	Status *InstanceStatus
+}
+
+// CloudProvider contains configuration info and functions for interacting with
+// cloud provider (GCE, AWS, etc).
+type CloudProvider interface {
+	// Name returns name of the cloud provider.
+	Name() string
+
+	// NodeGroups returns all node groups configured for this cloud provider.
+	NodeGroups() []NodeGroup
+
+	// NodeGroupForNode returns the node group for the given node, nil if the node
+	// should not be processed by cluster autoscaler, or non-nil error if such
+	// occurred. Must be implemented.
+	NodeGroupForNode(node *apiv1.Node) (NodeGroup, error)
+
+	// HasInstance returns whether a given node has a corresponding instance in this cloud provider
+	HasInstance(node *apiv1.Node) (bool, error)
+
+	// Pricing returns pricing model for this cloud provider or error if not available.
+	Pricing() (interface{}, error)
+
+	// GetAvailableMachineTypes get all machine types that can be requested from the cloud provider.
+	GetAvailableMachineTypes() ([]string, error)
+
+	// Cleanup cleans up open resources before the cloud provider is destroyed, i.e. go routines etc.
+	Cleanup() error
+
+	// Refresh is called before every main loop and can be used to dynamically update cloud provider state.
+	Refresh() error
+
+	// GetInstanceID gets the instance ID for the specified node.
+	GetInstanceID(node *apiv1.Node) string
+
+	// InstancesExist checks which of the given provider ids (format: <nodeGroup providerID scheme>://<id>)
+	// still exist as instances in the underlying cloud. Returns a map keyed by providerID with a boolean
+	// reporting whether the instance still exists. Implementations that do not support a bulk existence
+	// check should return ErrNotImplemented so callers can fall back to a per-nodegroup check.
+	InstancesExist(providerIDs []string) (map[string]bool, error)
+}
+
+// NodeGroup contains configuration info and functions to control a set of nodes that have the same capacity
+// and set of labels.
+type NodeGroup interface {
+	// MaxSize returns maximum size of the node group.
+	MaxSize() int
+
+	// MinSize returns minimum size of the node group.
+	MinSize() int
+
+	// TargetSize returns the current target size of the node group. It is possible that the
+	// number of nodes in Kubernetes is different at the moment but should be equal to Size()
+	// once everything stabilizes (new nodes finish startup and registration or removed nodes
+	// are deleted completely).
+	TargetSize() (int, error)
+
+	// IncreaseSize increases the size of the node group. To delete a node you need
+	// to explicitly name it and use DeleteNode. This function should wait until
+	// node group size is updated.
+	IncreaseSize(delta int) error
+
+	// DeleteNodes deletes nodes from this node group. Error is returned either on
+	// failure or if the given node doesn't belong to this node group.
+	DeleteNodes([]*apiv1.Node) error
+
+	// DecreaseTargetSize decreases the target size of the node group. This function
+	// doesn't permit to delete any existing node and can be used only to reduce the
+	// request for new nodes that have not been yet fulfilled.
+	DecreaseTargetSize(delta int) error
+
+	// Id returns an unique identifier of the node group.
+	Id() string
+
+	// Debug returns a string containing all information regarding this node group.
+	Debug() string
+
+	// Nodes returns a list of all nodes that belong to this node group.
+	// It is required that Instance objects returned by this method have Id field set.
+	// Other fields are optional.
+	Nodes() ([]Instance, error)
+
+	// Attach re-attaches the given instances (by provider id) to this node group.
+	// This is used to heal drift where an instance exists in the underlying cloud
+	// resource (e.g. an instance group) but is not currently considered part of
+	// any tracked node group. Implementations that cannot re-attach instances
+	// should return ErrNotImplemented.
+	Attach(providerIDs []string) error
+
+	// TemplateNodeInfo returns a schedulerframework.NodeInfo structure of an empty
+	// (as if just started) node. This will be used in scale-up simulations to
+	// predict what would a new node look like if a node group was expanded.
+	TemplateNodeInfo() (*schedulerframework.NodeInfo, error)
+
+	// Exist checks if the node group really exists on the cloud provider side.
+	Exist() bool
+
+	// Create creates the node group on the cloud provider side.
+	Create() (NodeGroup, error)
+
+	// Delete deletes the node group on the cloud provider side.
+	Delete() error
+
+	// Autoprovisioned returns true if the node group is autoprovisioned.
+	Autoprovisioned() bool
+}
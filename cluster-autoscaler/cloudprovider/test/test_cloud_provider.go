@@ -0,0 +1,325 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package test provides a fake, in-memory cloudprovider.CloudProvider implementation used
+// by unit tests across the autoscaler.
+package test
+
+import (
+	"fmt"
+	"sync"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// HasInstanceFunc allows tests to override the default HasInstance behavior of TestCloudProvider.
+type HasInstanceFunc func(string) (bool, error)
+
+// InstancesExistFunc allows tests to override the default InstancesExist behavior of TestCloudProvider.
+type InstancesExistFunc func(providerIDs []string) (map[string]bool, error)
+
+// TestCloudProvider is a dummy cloud provider to be used in tests.
+type TestCloudProvider struct {
+	sync.Mutex
+	nodes            map[string]string
+	instanceStatuses map[string]*cloudprovider.InstanceStatus
+	groups           map[string]cloudprovider.NodeGroup
+	onScaleUp        func(string, int) error
+	onScaleDown      func(string, string) error
+	hasInstance      HasInstanceFunc
+	instancesExist   InstancesExistFunc
+}
+
+// NewTestCloudProvider builds new TestCloudProvider
+func NewTestCloudProvider(onScaleUp func(string, int) error, onScaleDown func(string, string) error) *TestCloudProvider {
+	return &TestCloudProvider{
+		nodes:            make(map[string]string),
+		instanceStatuses: make(map[string]*cloudprovider.InstanceStatus),
+		groups:           make(map[string]cloudprovider.NodeGroup),
+		onScaleUp:        onScaleUp,
+		onScaleDown:      onScaleDown,
+		hasInstance:      func(string) (bool, error) { return true, nil },
+	}
+}
+
+// NewTestNodeDeletionDetectionCloudProvider builds new TestCloudProvider with deletion detection.
+func NewTestNodeDeletionDetectionCloudProvider(onScaleUp func(string, int) error, onScaleDown func(string, string) error,
+	hasInstance HasInstanceFunc) *TestCloudProvider {
+	return &TestCloudProvider{
+		nodes:            make(map[string]string),
+		instanceStatuses: make(map[string]*cloudprovider.InstanceStatus),
+		groups:           make(map[string]cloudprovider.NodeGroup),
+		onScaleUp:        onScaleUp,
+		onScaleDown:      onScaleDown,
+		hasInstance:      hasInstance,
+	}
+}
+
+// Name returns name of the cloud provider.
+func (tcp *TestCloudProvider) Name() string {
+	return "TestCloudProvider"
+}
+
+// NodeGroups returns all node groups configured for this cloud provider.
+func (tcp *TestCloudProvider) NodeGroups() []cloudprovider.NodeGroup {
+	tcp.Lock()
+	defer tcp.Unlock()
+
+	result := make([]cloudprovider.NodeGroup, 0, len(tcp.groups))
+	for _, group := range tcp.groups {
+		result = append(result, group)
+	}
+	return result
+}
+
+// NodeGroupForNode returns the node group for the given node.
+func (tcp *TestCloudProvider) NodeGroupForNode(node *apiv1.Node) (cloudprovider.NodeGroup, error) {
+	tcp.Lock()
+	defer tcp.Unlock()
+
+	groupName, found := tcp.nodes[node.Name]
+	if !found {
+		return nil, nil
+	}
+	group, found := tcp.groups[groupName]
+	if !found {
+		return nil, nil
+	}
+	return group, nil
+}
+
+// HasInstance returns whether a given node has a corresponding instance in this cloud provider
+func (tcp *TestCloudProvider) HasInstance(node *apiv1.Node) (bool, error) {
+	return tcp.hasInstance(node.Name)
+}
+
+// InstancesExist checks which of the given provider ids still exist.
+func (tcp *TestCloudProvider) InstancesExist(providerIDs []string) (map[string]bool, error) {
+	if tcp.instancesExist == nil {
+		return nil, cloudprovider.ErrNotImplemented
+	}
+	return tcp.instancesExist(providerIDs)
+}
+
+// SetInstancesExist sets the InstancesExist override used in tests that exercise the bulk existence API.
+func (tcp *TestCloudProvider) SetInstancesExist(f InstancesExistFunc) {
+	tcp.Lock()
+	defer tcp.Unlock()
+	tcp.instancesExist = f
+}
+
+// Pricing returns pricing model for this cloud provider or error if not available.
+func (tcp *TestCloudProvider) Pricing() (interface{}, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
+// GetAvailableMachineTypes get all machine types that can be requested from the cloud provider.
+func (tcp *TestCloudProvider) GetAvailableMachineTypes() ([]string, error) {
+	return []string{}, nil
+}
+
+// Cleanup cleans up open resources before the cloud provider is destroyed, i.e. go routines etc.
+func (tcp *TestCloudProvider) Cleanup() error {
+	return nil
+}
+
+// Refresh is called before every main loop and can be used to dynamically update cloud provider state.
+func (tcp *TestCloudProvider) Refresh() error {
+	return nil
+}
+
+// GetInstanceID gets the instance ID for the specified node.
+func (tcp *TestCloudProvider) GetInstanceID(node *apiv1.Node) string {
+	return node.Spec.ProviderID
+}
+
+// AddNodeGroup adds a node group to the cloud provider.
+func (tcp *TestCloudProvider) AddNodeGroup(id string, min int, max int, size int) {
+	tcp.Lock()
+	defer tcp.Unlock()
+	tcp.groups[id] = &TestNodeGroup{
+		cloudProvider: tcp,
+		id:            id,
+		minSize:       min,
+		maxSize:       max,
+		targetSize:    size,
+	}
+}
+
+// AddNode adds the given node to the group.
+func (tcp *TestCloudProvider) AddNode(groupID string, node *apiv1.Node) {
+	tcp.Lock()
+	defer tcp.Unlock()
+	tcp.nodes[node.Name] = groupID
+}
+
+// AddPendingInstance registers a cloud provider instance with no corresponding Kubernetes Node
+// yet, in the given InstanceState, as if the provider had created it but it hadn't booted far
+// enough to register. providerID also becomes the instance's entry in the group's Nodes() list.
+func (tcp *TestCloudProvider) AddPendingInstance(groupID string, providerID string, state cloudprovider.InstanceState) {
+	tcp.Lock()
+	defer tcp.Unlock()
+	tcp.nodes[providerID] = groupID
+	tcp.instanceStatuses[providerID] = &cloudprovider.InstanceStatus{State: state}
+}
+
+// DeleteNode removes the given node from its group, as if it was deleted on the cloud provider side.
+func (tcp *TestCloudProvider) DeleteNode(node *apiv1.Node) {
+	tcp.Lock()
+	defer tcp.Unlock()
+	delete(tcp.nodes, node.Name)
+}
+
+// GetNodeGroup returns node group by id.
+func (tcp *TestCloudProvider) GetNodeGroup(id string) cloudprovider.NodeGroup {
+	tcp.Lock()
+	defer tcp.Unlock()
+	return tcp.groups[id]
+}
+
+// TestNodeGroup is a dummy node group used in tests.
+type TestNodeGroup struct {
+	sync.Mutex
+	cloudProvider *TestCloudProvider
+	id            string
+	maxSize       int
+	minSize       int
+	targetSize    int
+}
+
+// MaxSize returns maximum size of the node group.
+func (tng *TestNodeGroup) MaxSize() int {
+	tng.Lock()
+	defer tng.Unlock()
+	return tng.maxSize
+}
+
+// MinSize returns minimum size of the node group.
+func (tng *TestNodeGroup) MinSize() int {
+	tng.Lock()
+	defer tng.Unlock()
+	return tng.minSize
+}
+
+// TargetSize returns the current target size of the node group.
+func (tng *TestNodeGroup) TargetSize() (int, error) {
+	tng.Lock()
+	defer tng.Unlock()
+	return tng.targetSize, nil
+}
+
+// SetTargetSize sets the target size of the node group, used in tests.
+func (tng *TestNodeGroup) SetTargetSize(size int) {
+	tng.Lock()
+	defer tng.Unlock()
+	tng.targetSize = size
+}
+
+// IncreaseSize increases the size of the node group.
+func (tng *TestNodeGroup) IncreaseSize(delta int) error {
+	tng.Lock()
+	id := tng.id
+	tng.targetSize += delta
+	tng.Unlock()
+
+	if tng.cloudProvider.onScaleUp != nil {
+		return tng.cloudProvider.onScaleUp(id, delta)
+	}
+	return nil
+}
+
+// DeleteNodes deletes nodes from this node group.
+func (tng *TestNodeGroup) DeleteNodes(nodes []*apiv1.Node) error {
+	for _, node := range nodes {
+		tng.Lock()
+		id := tng.id
+		tng.targetSize--
+		tng.Unlock()
+		if tng.cloudProvider.onScaleDown != nil {
+			if err := tng.cloudProvider.onScaleDown(id, node.Name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// DecreaseTargetSize decreases the target size of the node group.
+func (tng *TestNodeGroup) DecreaseTargetSize(delta int) error {
+	tng.Lock()
+	defer tng.Unlock()
+	tng.targetSize += delta
+	return nil
+}
+
+// Id returns an unique identifier of the node group.
+func (tng *TestNodeGroup) Id() string {
+	return tng.id
+}
+
+// Debug returns a string containing all information regarding this node group.
+func (tng *TestNodeGroup) Debug() string {
+	return fmt.Sprintf("%s target:%d min:%d max:%d", tng.id, tng.targetSize, tng.minSize, tng.maxSize)
+}
+
+// Nodes returns a list of all nodes that belong to this node group.
+func (tng *TestNodeGroup) Nodes() ([]cloudprovider.Instance, error) {
+	tng.cloudProvider.Lock()
+	defer tng.cloudProvider.Unlock()
+
+	instances := make([]cloudprovider.Instance, 0)
+	for node, group := range tng.cloudProvider.nodes {
+		if group == tng.id {
+			instances = append(instances, cloudprovider.Instance{
+				Id:     node,
+				Status: tng.cloudProvider.instanceStatuses[node],
+			})
+		}
+	}
+	return instances, nil
+}
+
+// Attach is not supported by TestNodeGroup and always returns ErrNotImplemented.
+func (tng *TestNodeGroup) Attach(providerIDs []string) error {
+	return cloudprovider.ErrNotImplemented
+}
+
+// TemplateNodeInfo is not supported by TestNodeGroup and always returns ErrNotImplemented.
+func (tng *TestNodeGroup) TemplateNodeInfo() (*schedulerframework.NodeInfo, error) {
+	return nil, cloudprovider.ErrNotImplemented
+}
+
+// Exist checks if the node group really exists on the cloud provider side.
+func (tng *TestNodeGroup) Exist() bool {
+	return true
+}
+
+// Create creates nothing: a TestNodeGroup always exists, so it returns ErrAlreadyExist.
+func (tng *TestNodeGroup) Create() (cloudprovider.NodeGroup, error) {
+	return nil, cloudprovider.ErrAlreadyExist
+}
+
+// Delete is not supported by TestNodeGroup and always returns ErrNotImplemented.
+func (tng *TestNodeGroup) Delete() error {
+	return cloudprovider.ErrNotImplemented
+}
+
+// Autoprovisioned returns true if the node group is autoprovisioned.
+func (tng *TestNodeGroup) Autoprovisioned() bool {
+	return false
+}
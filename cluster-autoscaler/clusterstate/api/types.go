@@ -0,0 +1,124 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package api defines the status types published by the cluster autoscaler to the
+// kube-system/cluster-autoscaler-status ConfigMap.
+package api
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ClusterAutoscalerConditionType describes the type of cluster autoscaler status condition.
+type ClusterAutoscalerConditionType string
+
+const (
+	// ClusterAutoscalerHealth type of cluster autoscaler condition.
+	ClusterAutoscalerHealth ClusterAutoscalerConditionType = "Health"
+	// ClusterAutoscalerScaleUp type of cluster autoscaler condition.
+	ClusterAutoscalerScaleUp ClusterAutoscalerConditionType = "ScaleUp"
+	// ClusterAutoscalerScaleDown type of cluster autoscaler condition.
+	ClusterAutoscalerScaleDown ClusterAutoscalerConditionType = "ScaleDown"
+	// ClusterAutoscalerBackoff type of cluster autoscaler condition, reported per node group
+	// while it is being penalized by the exponential scale-up failure backoff.
+	ClusterAutoscalerBackoff ClusterAutoscalerConditionType = "Backoff"
+	// ClusterAutoscalerNodeGroupDisabled type of cluster autoscaler condition, reported per
+	// node group once it has exceeded MaxFailedScaleUpsPerNodeGroup scale-up failures and been
+	// excluded from further scale-ups until an operator clears its failure history.
+	ClusterAutoscalerNodeGroupDisabled ClusterAutoscalerConditionType = "NodeGroupDisabled"
+	// ClusterAutoscalerNodePressure type of cluster autoscaler condition, reported per node
+	// group, indicating whether any of its nodes currently report a NetworkUnavailable,
+	// DiskPressure, MemoryPressure or PIDPressure condition true.
+	ClusterAutoscalerNodePressure ClusterAutoscalerConditionType = "NodePressure"
+)
+
+// Status of the cluster autoscaler condition.
+type ClusterAutoscalerConditionStatus string
+
+const (
+	// ClusterAutoscalerHealthy status.
+	ClusterAutoscalerHealthy ClusterAutoscalerConditionStatus = "Healthy"
+	// ClusterAutoscalerUnhealthy status.
+	ClusterAutoscalerUnhealthy ClusterAutoscalerConditionStatus = "Unhealthy"
+	// ClusterAutoscalerInProgress status.
+	ClusterAutoscalerInProgress ClusterAutoscalerConditionStatus = "InProgress"
+	// ClusterAutoscalerNoActivity status.
+	ClusterAutoscalerNoActivity ClusterAutoscalerConditionStatus = "NoActivity"
+	// ClusterAutoscalerNotNeeded status.
+	ClusterAutoscalerNotNeeded ClusterAutoscalerConditionStatus = "NotNeeded"
+	// ClusterAutoscalerCandidatesPresent status.
+	ClusterAutoscalerCandidatesPresent ClusterAutoscalerConditionStatus = "CandidatesPresent"
+	// ClusterAutoscalerNoCandidates status.
+	ClusterAutoscalerNoCandidates ClusterAutoscalerConditionStatus = "NoCandidates"
+	// ClusterAutoscalerBackedOff status, reported on the ClusterAutoscalerBackoff condition
+	// while a node group is being penalized by the exponential scale-up failure backoff.
+	ClusterAutoscalerBackedOff ClusterAutoscalerConditionStatus = "BackedOff"
+	// ClusterAutoscalerNotBackedOff status, reported on the ClusterAutoscalerBackoff condition
+	// for node groups with no active scale-up backoff.
+	ClusterAutoscalerNotBackedOff ClusterAutoscalerConditionStatus = "NotBackedOff"
+	// ClusterAutoscalerDisabled status, reported on the ClusterAutoscalerNodeGroupDisabled
+	// condition for a node group that has exceeded MaxFailedScaleUpsPerNodeGroup failures.
+	ClusterAutoscalerDisabled ClusterAutoscalerConditionStatus = "Disabled"
+	// ClusterAutoscalerEnabled status, reported on the ClusterAutoscalerNodeGroupDisabled
+	// condition for a node group with no active failure-count disablement.
+	ClusterAutoscalerEnabled ClusterAutoscalerConditionStatus = "Enabled"
+	// ClusterAutoscalerPressureDetected status, reported on the ClusterAutoscalerNodePressure
+	// condition for a node group with at least one node reporting a pressure condition true.
+	ClusterAutoscalerPressureDetected ClusterAutoscalerConditionStatus = "PressureDetected"
+	// ClusterAutoscalerNoPressure status, reported on the ClusterAutoscalerNodePressure
+	// condition for a node group with no nodes currently under pressure.
+	ClusterAutoscalerNoPressure ClusterAutoscalerConditionStatus = "NoPressure"
+)
+
+// ClusterAutoscalerCondition is a condition of a certain aspect of cluster autoscaler health/activity.
+type ClusterAutoscalerCondition struct {
+	// Type of the condition.
+	Type ClusterAutoscalerConditionType `json:"type"`
+	// Status of the condition.
+	Status ClusterAutoscalerConditionStatus `json:"status"`
+	// LastProbeTime is the last time the condition was examined.
+	LastProbeTime metav1.Time `json:"lastProbeTime"`
+	// LastTransitionTime is the last time the condition transitioned to a new status.
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+	// Message is a human readable description of the condition.
+	Message string `json:"message,omitempty"`
+}
+
+// NodeGroupStatus represents the status of a single node group.
+type NodeGroupStatus struct {
+	// ProviderID is the id of the node group as reported by the cloud provider.
+	ProviderID string `json:"providerID"`
+	// Conditions is a list of conditions describing the node group's health and activity.
+	Conditions []ClusterAutoscalerCondition `json:"conditions"`
+}
+
+// ClusterAutoscalerStatus is the root status object persisted to the status ConfigMap.
+type ClusterAutoscalerStatus struct {
+	// ClusterwideConditions describes the cluster-wide state.
+	ClusterwideConditions []ClusterAutoscalerCondition `json:"clusterwideConditions"`
+	// NodeGroupStatuses describes the state of each node group.
+	NodeGroupStatuses []NodeGroupStatus `json:"nodeGroupStatuses"`
+}
+
+// GetConditionByType returns the condition of a given type, or nil if it is not present.
+func GetConditionByType(conditionType ClusterAutoscalerConditionType, conditions []ClusterAutoscalerCondition) *ClusterAutoscalerCondition {
+	for _, cond := range conditions {
+		if cond.Type == conditionType {
+			return &cond
+		}
+	}
+	return nil
+}
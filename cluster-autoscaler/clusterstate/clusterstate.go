@@ -0,0 +1,1532 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package clusterstate tracks the state of cluster nodes as seen by Kubernetes and by the
+// cloud provider, and derives from it whether the cluster (and each node group) is healthy,
+// scaling, or should be backed off from further scale-ups.
+package clusterstate
+
+import (
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/api"
+	"k8s.io/autoscaler/cluster-autoscaler/clusterstate/utils"
+	"k8s.io/autoscaler/cluster-autoscaler/metrics"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/backoff"
+	"k8s.io/autoscaler/cluster-autoscaler/utils/deletetaint"
+
+	klog "k8s.io/klog/v2"
+	schedulerframework "k8s.io/kubernetes/pkg/scheduler/framework"
+)
+
+// ScaleUpRequest contains information about the currently expected scale-up of a single
+// node group.
+type ScaleUpRequest struct {
+	// NodeGroup is the node group targeted by the scale-up.
+	NodeGroup cloudprovider.NodeGroup
+	// Increase is the number of nodes requested by the scale-up. It is not reduced as those
+	// nodes register with Kubernetes.
+	Increase int
+	// Time is the time of the last update of this scale-up request.
+	Time time.Time
+	// ExpectedAddTime is the time by which the requested nodes are expected to register.
+	ExpectedAddTime time.Time
+}
+
+// ScaleDownRequest contains information about the pending deletion of a single node.
+type ScaleDownRequest struct {
+	// NodeGroup is the node group the deleted node belonged to.
+	NodeGroup cloudprovider.NodeGroup
+	// NodeName is the name of the node being deleted.
+	NodeName string
+	// Time is the time the deletion was requested.
+	Time time.Time
+	// ExpectedDeleteTime is the time by which the node is expected to be gone.
+	ExpectedDeleteTime time.Time
+}
+
+// ScaleUpFailure contains information about a failed scale-up.
+type ScaleUpFailure struct {
+	// NodeGroup is the node group that failed to scale up.
+	NodeGroup cloudprovider.NodeGroup
+	// Reason is why the scale-up failed.
+	Reason metrics.FailedScaleUpReason
+	// Time is when the failure was observed.
+	Time time.Time
+}
+
+const (
+	// initialScaleUpBackoffDuration is the backoff duration applied after the first observed
+	// scale-up failure for a given (node group, reason) pair.
+	initialScaleUpBackoffDuration = 5 * time.Minute
+	// maxScaleUpBackoffDuration caps the exponential growth of the backoff duration.
+	maxScaleUpBackoffDuration = 30 * time.Minute
+	// scaleUpBackoffJitterFraction is the fraction of the computed backoff duration added on
+	// top of it, at random, to avoid every node group coming off backoff at the same instant.
+	scaleUpBackoffJitterFraction = 0.1
+)
+
+// scaleUpBackoffKey identifies an independently-backed-off (node group, failure reason) pair.
+type scaleUpBackoffKey struct {
+	nodeGroupID string
+	reason      metrics.FailedScaleUpReason
+}
+
+// scaleUpBackoffEntry tracks the exponential backoff state for a single scaleUpBackoffKey.
+type scaleUpBackoffEntry struct {
+	attempt  int
+	until    time.Time
+	reason   metrics.FailedScaleUpReason
+}
+
+// BackoffStatus describes the current backoff state of a node group.
+type BackoffStatus struct {
+	// Backoff is true if a scale-up backoff is tracked for the node group, i.e. it has failed to
+	// scale up and has neither reached its target size nor been reset since. It stays true after
+	// Until has passed; Until tells whether the backoff is still in effect.
+	Backoff bool
+	// Until is the time the backoff expires. Zero if Backoff is false.
+	Until time.Time
+	// Reason is the failure reason that caused the current (longest-running) backoff.
+	Reason metrics.FailedScaleUpReason
+	// Attempt is the number of consecutive failures behind the current backoff.
+	Attempt int
+}
+
+// ClusterStateRegistryConfig is the configuration of ClusterStateRegistry.
+type ClusterStateRegistryConfig struct {
+	// MaxTotalUnreadyPercentage is the maximum percentage of unready nodes after which CA
+	// stops all operations.
+	MaxTotalUnreadyPercentage float64
+	// OkTotalUnreadyCount is the number of allowed unready nodes, irrespective of
+	// MaxTotalUnreadyPercentage.
+	OkTotalUnreadyCount int
+	// MaxNodeProvisionTime is the time after which an expected scale-up is considered
+	// to have timed out.
+	MaxNodeProvisionTime time.Duration
+	// Reconciliation configures the proactive orphaned-node reconciliation pass run as part
+	// of UpdateNodes. Leaving it at its zero value disables all reconciliation actions, but
+	// orphaned nodes are still classified and exposed through GetOrphanedNodes().
+	Reconciliation ReconciliationConfig
+	// ReadinessClassifiers are consulted, in addition to the default NodeReady/not-ready-taint
+	// classifier, when deciding whether a node is Ready, NotStarted or Unready. Operators with
+	// specialized boot sequences (a CNI plugin, a GPU driver DaemonSet, a storage driver) can
+	// register a classifier here so affected nodes aren't counted Ready, and don't trigger the
+	// MaxNodeProvisionTime timeout, while a required condition is still pending.
+	ReadinessClassifiers []NodeReadinessClassifier
+	// InstanceExistenceProbeInterval is the minimum time between two consecutive bulk
+	// instance-existence probes via CloudProvider.InstancesExist. A probe marks any registered
+	// node whose instance has disappeared as deleted immediately, instead of waiting for its
+	// node group's cached Nodes() listing to be refreshed. Zero disables probing; providers
+	// that don't implement InstancesExist fall back to the existing cache-based detection.
+	InstanceExistenceProbeInterval time.Duration
+	// MaxFailedScaleUpsPerNodeGroup is the number of scale-up failures, within
+	// FailureCountingWindow, that a node group may have; once it has more than that it is marked
+	// disabled: IsNodeGroupSafeToScaleUp
+	// returns false for it until an operator calls ClearNodeGroupFailures, or its target size is
+	// externally reduced to zero. Zero disables this check, leaving exponential backoff as the
+	// only throttle on a chronically failing node group.
+	MaxFailedScaleUpsPerNodeGroup int
+	// FailureCountingWindow is the sliding window over which scale-up failures are counted
+	// towards MaxFailedScaleUpsPerNodeGroup. Defaults to defaultFailureCountingWindow if zero.
+	FailureCountingWindow time.Duration
+	// NodePressureGracePeriod is how long a node's NetworkUnavailable, DiskPressure,
+	// MemoryPressure or PIDPressure condition has to stay true before IsNodeUsableForScheduling
+	// stops counting the node as Ready, even though its NodeReady condition is still true.
+	// Defaults to defaultNodePressureGracePeriod if zero.
+	NodePressureGracePeriod time.Duration
+}
+
+// defaultFailureCountingWindow is used in place of ClusterStateRegistryConfig.FailureCountingWindow
+// when it is left at its zero value.
+const defaultFailureCountingWindow = time.Hour
+
+// defaultNodePressureGracePeriod is used in place of ClusterStateRegistryConfig.NodePressureGracePeriod
+// when it is left at its zero value.
+const defaultNodePressureGracePeriod = 5 * time.Minute
+
+// defaultMaxNodeProvisionTime is used by the default NodeReadinessClassifier in place of
+// ClusterStateRegistryConfig.MaxNodeProvisionTime when it is left at its zero value.
+const defaultMaxNodeProvisionTime = 15 * time.Minute
+
+// pressureConditionTypes are the node conditions, other than NodeReady itself, that indicate a
+// node is under enough strain that it shouldn't be considered reliably usable for scheduling.
+var pressureConditionTypes = []apiv1.NodeConditionType{
+	apiv1.NodeNetworkUnavailable,
+	apiv1.NodeDiskPressure,
+	apiv1.NodeMemoryPressure,
+	apiv1.NodePIDPressure,
+}
+
+// OrphanState classifies a node/instance pair as seen by Kubernetes and by the cloud provider.
+type OrphanState string
+
+const (
+	// OrphanStateKubernetesOnly means a Kubernetes Node object exists with no backing cloud
+	// provider instance.
+	OrphanStateKubernetesOnly OrphanState = "KubernetesOnly"
+	// OrphanStateCloudOnly means a cloud provider instance exists with no matching Kubernetes
+	// Node object.
+	OrphanStateCloudOnly OrphanState = "CloudOnly"
+	// OrphanStateDetached means a cloud provider instance exists but is not attached to any
+	// node group tracked by the autoscaler.
+	OrphanStateDetached OrphanState = "Detached"
+)
+
+// OrphanedNode describes a single node or instance whose Kubernetes and cloud provider state
+// has drifted apart.
+type OrphanedNode struct {
+	// NodeName is the Kubernetes node name, if any.
+	NodeName string
+	// ProviderID is the cloud provider instance id, if any.
+	ProviderID string
+	// NodeGroupID is the node group the node/instance belongs (or belonged) to.
+	NodeGroupID string
+	// State classifies the kind of drift observed.
+	State OrphanState
+	// FirstObserved is the time this node/instance was first classified as orphaned.
+	FirstObserved time.Time
+}
+
+// ReconciliationAction repairs a single orphaned node/instance, e.g. by deleting a stale
+// Kubernetes Node object or re-attaching a detached instance to its node group.
+type ReconciliationAction func(orphan OrphanedNode) error
+
+// ReconciliationConfig configures the proactive orphan reconciliation pass. Each action is
+// optional; a nil action means orphans of that class are classified and reported (via
+// GetOrphanedNodes) but not automatically repaired.
+type ReconciliationConfig struct {
+	// GracePeriod is how long a node/instance must stay classified as orphaned before an
+	// action is invoked for it.
+	GracePeriod time.Duration
+	// OnKubernetesOnly is invoked once GracePeriod has elapsed for Kubernetes Node objects
+	// with no backing instance. A typical implementation deletes the stale Node object.
+	OnKubernetesOnly ReconciliationAction
+	// OnCloudOnly is invoked once GracePeriod has elapsed for cloud provider instances with
+	// no matching Kubernetes Node.
+	OnCloudOnly ReconciliationAction
+	// OnDetached is invoked once GracePeriod has elapsed for instances that are not attached
+	// to any tracked node group. A typical implementation calls NodeGroup.Attach to
+	// re-associate the instance with its originating node group. reconcileOrphans only
+	// classifies KubernetesOnly and CloudOnly orphans, never Detached ones, so it does not
+	// invoke this action.
+	OnDetached ReconciliationAction
+}
+
+// IncorrectNodeGroupSize contains information about how much the current size of the node
+// group differs from the expected size. Incorrect size state has to exist for a pre-defined
+// period of time before it's acted upon.
+type IncorrectNodeGroupSize struct {
+	// ExpectedSize is the size of the node group measured on the cloud provider side.
+	ExpectedSize int
+	// CurrentSize is the size of the node group measured on the Kubernetes side.
+	CurrentSize int
+	// FirstObserved is the time when the given difference occurred.
+	FirstObserved time.Time
+}
+
+// UnregisteredNode contains information about a node that is present in the cloud provider
+// but not yet in Kubernetes.
+type UnregisteredNode struct {
+	// Node is a dummy node that contains only the information about the node name and provider id.
+	Node *apiv1.Node
+	// UnregisteredSince is the time when the node was first spotted as unregistered.
+	UnregisteredSince time.Time
+	// NodeGroup is the id of the node group the instance belongs to.
+	NodeGroup string
+}
+
+// PendingNode describes a cloud provider instance that a node group has already created - and
+// that is still Creating or Running by the provider's own account - but that hasn't yet
+// registered as a Kubernetes Node. Unlike UnregisteredNode, a Pending instance is known-good
+// progress towards a scale-up and resets that node group's provisioning timer instead of
+// eventually counting towards a scale-up timeout.
+type PendingNode struct {
+	// ProviderID is the cloud provider instance id.
+	ProviderID string
+	// State is the state the cloud provider last reported for this instance.
+	State cloudprovider.InstanceState
+	// FirstObserved is the time this instance was first observed pending.
+	FirstObserved time.Time
+}
+
+// Readiness contains readiness information about a group of nodes.
+type Readiness struct {
+	// Ready is a list of ready nodes.
+	Ready []string
+	// Unready is a list of nodes that are unready for a long time, i.e. past their grace period.
+	Unready []string
+	// NotStarted is a list of nodes that are unready because they have just started.
+	NotStarted []string
+	// LongUnregistered is a list of nodes that failed to register for at least MaxNodeProvisionTime.
+	LongUnregistered []string
+	// Unregistered is a list of nodes that are not yet registered.
+	Unregistered []string
+	// Registered is a list of all registered nodes.
+	Registered []string
+	// ToBeDeleted is a list of nodes tainted for deletion as part of an in-flight scale-down.
+	// They are still registered in Kubernetes, but are excluded from Ready/Unready/NotStarted
+	// since they are already accounted for by the scale-down path.
+	ToBeDeleted []string
+	// Deleted is a list of nodes that have been deleted in the cloud provider but are still
+	// registered in Kubernetes.
+	Deleted []string
+	// NetworkUnavailable is a list of nodes currently reporting their NetworkUnavailable
+	// condition true.
+	NetworkUnavailable []string
+	// DiskPressure is a list of nodes currently reporting their DiskPressure condition true.
+	DiskPressure []string
+	// MemoryPressure is a list of nodes currently reporting their MemoryPressure condition true.
+	MemoryPressure []string
+	// PIDPressure is a list of nodes currently reporting their PIDPressure condition true.
+	PIDPressure []string
+	// Breakdown gives, for every registered NodeReadinessClassifier that flagged at least one
+	// node as NotStarted or Unready, the names of the nodes it flagged and under which state.
+	// The default classifier is reported under the name "Default".
+	Breakdown map[string]ClassifierVerdicts
+}
+
+// ClassifierVerdicts is the set of nodes a single NodeReadinessClassifier flagged as NotStarted
+// or Unready.
+type ClassifierVerdicts struct {
+	// NotStarted is a list of nodes this classifier considers still booting.
+	NotStarted []string
+	// Unready is a list of nodes this classifier considers unready for reasons other than a
+	// still-pending boot sequence.
+	Unready []string
+}
+
+// AcceptableRange contains information about acceptable size of a node group.
+type AcceptableRange struct {
+	// MinNodes is the minimum number of nodes that determine an acceptable range.
+	MinNodes int
+	// MaxNodes is the maximum number of nodes that determine an acceptable range.
+	MaxNodes int
+	// CurrentTarget is the current target size for the node group.
+	CurrentTarget int
+}
+
+// ClusterStateRegistry is a structure to keep track of cluster state, health, and ongoing
+// scale-up/scale-down activity across all node groups.
+type ClusterStateRegistry struct {
+	sync.Mutex
+
+	config        ClusterStateRegistryConfig
+	cloudProvider cloudprovider.CloudProvider
+	logRecorder   *utils.LogRecorder
+
+	scaleUpRequests   map[string]*ScaleUpRequest
+	scaleDownRequests []*ScaleDownRequest
+	scaleUpFailures   map[string][]ScaleUpFailure
+	scaleUpBackoffs   map[scaleUpBackoffKey]*scaleUpBackoffEntry
+
+	nodes                           []*apiv1.Node
+	cloudProviderNodeInstancesCache map[string][]cloudprovider.Instance
+
+	acceptableRanges        map[string]AcceptableRange
+	incorrectNodeGroupSizes map[string]IncorrectNodeGroupSize
+	unregisteredNodes       map[string]UnregisteredNode
+	pendingNodes            map[string]pendingNodeEntry
+	deletedNodes            map[string]bool
+	lastKnownNodeGroup      map[string]string
+	candidatesForScaleDown  map[string][]string
+	orphanedNodes           map[string]OrphanedNode
+	disabledNodeGroups      map[string]bool
+
+	lastInstanceExistenceProbe time.Time
+
+	totalReadiness        Readiness
+	perNodeGroupReadiness map[string]Readiness
+	readinessClassifier   *CompositeClassifier
+
+	backoff backoff.Backoff
+
+	lastStatus *api.ClusterAutoscalerStatus
+}
+
+// NewClusterStateRegistry creates a new ClusterStateRegistry.
+func NewClusterStateRegistry(cloudProvider cloudprovider.CloudProvider, config ClusterStateRegistryConfig,
+	logRecorder *utils.LogRecorder, backoff backoff.Backoff) *ClusterStateRegistry {
+	return &ClusterStateRegistry{
+		config:                  config,
+		cloudProvider:           cloudProvider,
+		logRecorder:             logRecorder,
+		scaleUpRequests:         make(map[string]*ScaleUpRequest),
+		scaleDownRequests:       make([]*ScaleDownRequest, 0),
+		scaleUpFailures:         make(map[string][]ScaleUpFailure),
+		scaleUpBackoffs:         make(map[scaleUpBackoffKey]*scaleUpBackoffEntry),
+		acceptableRanges:        make(map[string]AcceptableRange),
+		incorrectNodeGroupSizes: make(map[string]IncorrectNodeGroupSize),
+		unregisteredNodes:       make(map[string]UnregisteredNode),
+		pendingNodes:            make(map[string]pendingNodeEntry),
+		deletedNodes:            make(map[string]bool),
+		lastKnownNodeGroup:      make(map[string]string),
+		candidatesForScaleDown:  make(map[string][]string),
+		orphanedNodes:           make(map[string]OrphanedNode),
+		disabledNodeGroups:      make(map[string]bool),
+		perNodeGroupReadiness:   make(map[string]Readiness),
+		readinessClassifier:     NewCompositeClassifier(config.MaxNodeProvisionTime, config.ReadinessClassifiers...),
+		backoff:                 backoff,
+	}
+}
+
+// RegisterOrUpdateScaleUp registers scale-up for the given node group, or changes the node
+// count of an already registered scale-up (increasing a positive delta or decreasing it
+// towards zero, at which point the request is forgotten).
+func (csr *ClusterStateRegistry) RegisterOrUpdateScaleUp(nodeGroup cloudprovider.NodeGroup, delta int, currentTime time.Time) {
+	csr.Lock()
+	defer csr.Unlock()
+	csr.registerOrUpdateScaleUpNoLock(nodeGroup, delta, currentTime)
+}
+
+func (csr *ClusterStateRegistry) registerOrUpdateScaleUpNoLock(nodeGroup cloudprovider.NodeGroup, delta int, currentTime time.Time) {
+	scaleUpRequest, found := csr.scaleUpRequests[nodeGroup.Id()]
+	if !found && delta > 0 {
+		scaleUpRequest = &ScaleUpRequest{
+			NodeGroup:       nodeGroup,
+			Increase:        delta,
+			Time:            currentTime,
+			ExpectedAddTime: currentTime.Add(csr.config.MaxNodeProvisionTime),
+		}
+		csr.scaleUpRequests[nodeGroup.Id()] = scaleUpRequest
+		return
+	}
+	if !found {
+		// Negative delta with nothing registered - nothing to do.
+		return
+	}
+
+	// Only update times on positive delta, so a shrinking request doesn't reset the clock.
+	if delta > 0 {
+		scaleUpRequest.Time = currentTime
+		scaleUpRequest.ExpectedAddTime = currentTime.Add(csr.config.MaxNodeProvisionTime)
+	}
+	scaleUpRequest.Increase += delta
+
+	if scaleUpRequest.Increase <= 0 {
+		delete(csr.scaleUpRequests, nodeGroup.Id())
+	}
+}
+
+// RegisterScaleDown registers the scale-down of a single node.
+func (csr *ClusterStateRegistry) RegisterScaleDown(request *ScaleDownRequest) {
+	csr.Lock()
+	defer csr.Unlock()
+	csr.scaleDownRequests = append(csr.scaleDownRequests, request)
+}
+
+// RegisterFailedScaleUp records a scale-up failure for a node group.
+func (csr *ClusterStateRegistry) RegisterFailedScaleUp(nodeGroup cloudprovider.NodeGroup, reason metrics.FailedScaleUpReason, currentTime time.Time) {
+	csr.Lock()
+	defer csr.Unlock()
+	csr.registerFailedScaleUpNoLock(nodeGroup, reason, currentTime)
+}
+
+func (csr *ClusterStateRegistry) registerFailedScaleUpNoLock(nodeGroup cloudprovider.NodeGroup, reason metrics.FailedScaleUpReason, currentTime time.Time) {
+	metrics.RegisterFailedScaleUp(reason)
+	csr.scaleUpFailures[nodeGroup.Id()] = append(csr.scaleUpFailures[nodeGroup.Id()], ScaleUpFailure{
+		NodeGroup: nodeGroup,
+		Reason:    reason,
+		Time:      currentTime,
+	})
+	csr.backoff.Backoff(nodeGroup, nil, currentTime)
+	csr.backoffScaleUpNoLock(nodeGroup.Id(), reason, currentTime)
+	csr.updateNodeGroupDisabledNoLock(nodeGroup.Id(), currentTime)
+}
+
+// updateNodeGroupDisabledNoLock recomputes whether the node group is disabled, i.e. has more
+// than MaxFailedScaleUpsPerNodeGroup scale-up failures within the last FailureCountingWindow.
+// The underlying failure list itself is left untouched - only ClearNodeGroupFailures or the
+// node group's target size externally dropping to zero removes it.
+func (csr *ClusterStateRegistry) updateNodeGroupDisabledNoLock(nodeGroupID string, currentTime time.Time) {
+	if csr.config.MaxFailedScaleUpsPerNodeGroup <= 0 {
+		delete(csr.disabledNodeGroups, nodeGroupID)
+		return
+	}
+	window := csr.config.FailureCountingWindow
+	if window <= 0 {
+		window = defaultFailureCountingWindow
+	}
+
+	count := 0
+	for _, failure := range csr.scaleUpFailures[nodeGroupID] {
+		if currentTime.Sub(failure.Time) <= window {
+			count++
+		}
+	}
+	csr.disabledNodeGroups[nodeGroupID] = count > csr.config.MaxFailedScaleUpsPerNodeGroup
+}
+
+// IsNodeGroupDisabled returns true if the node group has exceeded MaxFailedScaleUpsPerNodeGroup
+// scale-up failures within FailureCountingWindow and should not be scaled up again until an
+// operator clears its failure history.
+func (csr *ClusterStateRegistry) IsNodeGroupDisabled(nodeGroup cloudprovider.NodeGroup) bool {
+	csr.Lock()
+	defer csr.Unlock()
+	return csr.disabledNodeGroups[nodeGroup.Id()]
+}
+
+// ClearNodeGroupFailures discards every recorded scale-up failure for the given node group,
+// re-enabling it for scale-ups if MaxFailedScaleUpsPerNodeGroup had disabled it.
+func (csr *ClusterStateRegistry) ClearNodeGroupFailures(nodeGroup cloudprovider.NodeGroup) {
+	csr.Lock()
+	defer csr.Unlock()
+	delete(csr.scaleUpFailures, nodeGroup.Id())
+	delete(csr.disabledNodeGroups, nodeGroup.Id())
+}
+
+// backoffScaleUpNoLock records another consecutive failure of the given reason for the node
+// group and computes the next retry time as min(maxBackoff, base*2^(attempt-1)) plus a random
+// jitter, so that a chronically failing node group backs off further and further while
+// healthy node groups keep scaling.
+func (csr *ClusterStateRegistry) backoffScaleUpNoLock(nodeGroupID string, reason metrics.FailedScaleUpReason, currentTime time.Time) {
+	key := scaleUpBackoffKey{nodeGroupID: nodeGroupID, reason: reason}
+	entry, found := csr.scaleUpBackoffs[key]
+	attempt := 1
+	if found {
+		attempt = entry.attempt + 1
+	}
+
+	duration := initialScaleUpBackoffDuration
+	for i := 1; i < attempt && duration < maxScaleUpBackoffDuration; i++ {
+		duration *= 2
+	}
+	if duration > maxScaleUpBackoffDuration {
+		duration = maxScaleUpBackoffDuration
+	}
+	jitter := time.Duration(rand.Int63n(int64(float64(duration) * scaleUpBackoffJitterFraction) + 1))
+
+	csr.scaleUpBackoffs[key] = &scaleUpBackoffEntry{
+		attempt: attempt,
+		until:   currentTime.Add(duration).Add(jitter),
+		reason:  reason,
+	}
+}
+
+// resetScaleUpBackoffNoLock clears every backoff entry tracked for the given node group,
+// regardless of failure reason.
+func (csr *ClusterStateRegistry) resetScaleUpBackoffNoLock(nodeGroupID string) {
+	for key := range csr.scaleUpBackoffs {
+		if key.nodeGroupID == nodeGroupID {
+			delete(csr.scaleUpBackoffs, key)
+		}
+	}
+}
+
+// GetBackoffStatus returns the current backoff state of the given node group, i.e. the
+// longest-running backoff across all failure reasons currently being tracked for it.
+func (csr *ClusterStateRegistry) GetBackoffStatus(nodeGroupID string) BackoffStatus {
+	csr.Lock()
+	defer csr.Unlock()
+
+	var worst *scaleUpBackoffEntry
+	for key, entry := range csr.scaleUpBackoffs {
+		if key.nodeGroupID != nodeGroupID {
+			continue
+		}
+		if worst == nil || entry.until.After(worst.until) {
+			worst = entry
+		}
+	}
+	if worst == nil {
+		return BackoffStatus{}
+	}
+	return BackoffStatus{
+		Backoff: true,
+		Until:   worst.until,
+		Reason:  worst.reason,
+		Attempt: worst.attempt,
+	}
+}
+
+// ResetBackoff clears all tracked scale-up backoff state for the given node group, allowing
+// it to be scaled up again immediately regardless of any pending exponential backoff.
+func (csr *ClusterStateRegistry) ResetBackoff(nodeGroupID string) {
+	csr.Lock()
+	defer csr.Unlock()
+	csr.resetScaleUpBackoffNoLock(nodeGroupID)
+}
+
+// GetScaleUpFailures returns the scale-up failures observed so far, keyed by node group id.
+func (csr *ClusterStateRegistry) GetScaleUpFailures() map[string][]ScaleUpFailure {
+	csr.Lock()
+	defer csr.Unlock()
+
+	result := make(map[string][]ScaleUpFailure)
+	for nodeGroupID, failures := range csr.scaleUpFailures {
+		result[nodeGroupID] = append([]ScaleUpFailure{}, failures...)
+	}
+	return result
+}
+
+func (csr *ClusterStateRegistry) clearScaleUpFailures() {
+	csr.scaleUpFailures = make(map[string][]ScaleUpFailure)
+}
+
+// updateScaleRequests removes scale-down requests that have expired, and turns timed out
+// scale-ups into scale-up failures. Fulfilled scale-ups are removed by
+// updateIncorrectNodeGroupSizes once their node group reaches its target size.
+func (csr *ClusterStateRegistry) updateScaleRequests(currentTime time.Time) {
+	// Clean up stale scale-down requests.
+	newScaleDownRequests := make([]*ScaleDownRequest, 0, len(csr.scaleDownRequests))
+	for _, request := range csr.scaleDownRequests {
+		if currentTime.Before(request.ExpectedDeleteTime) {
+			newScaleDownRequests = append(newScaleDownRequests, request)
+		}
+	}
+	csr.scaleDownRequests = newScaleDownRequests
+
+	// Time out scale-up requests that never materialized. A node group with at least one
+	// Pending instance is making visible progress, so its provisioning timer is extended
+	// instead of being timed out - only a node group with nothing but Unregistered instances
+	// (or none at all) is considered suspect.
+	for nodeGroupName, scaleUpRequest := range csr.scaleUpRequests {
+		if csr.hasPendingNodesNoLock(nodeGroupName) {
+			scaleUpRequest.ExpectedAddTime = currentTime.Add(csr.config.MaxNodeProvisionTime)
+			continue
+		}
+		if scaleUpRequest.ExpectedAddTime.Before(currentTime) {
+			klog.Warningf("Scale-up timed out for node group %v after %v", nodeGroupName,
+				currentTime.Sub(scaleUpRequest.Time))
+			csr.logRecorder.Eventf(apiv1.EventTypeWarning, "ScaleUpTimedOut",
+				"Nodes added to group %s failed to register within %v", nodeGroupName, csr.config.MaxNodeProvisionTime)
+			csr.registerFailedScaleUpNoLock(scaleUpRequest.NodeGroup, metrics.Timeout, currentTime)
+			delete(csr.scaleUpRequests, nodeGroupName)
+		}
+	}
+}
+
+// UpdateNodes updates the state of the registry with the current list of nodes, recomputing
+// readiness, unregistered/deleted nodes, and node group size correctness.
+func (csr *ClusterStateRegistry) UpdateNodes(nodes []*apiv1.Node, nodeInfosForGroups map[string]*schedulerframework.NodeInfo, currentTime time.Time) error {
+	csr.Lock()
+	defer csr.Unlock()
+
+	csr.nodes = nodes
+	cloudProviderNodeInstances, err := csr.getCloudProviderNodeInstances()
+	if err != nil {
+		return err
+	}
+
+	notRegistered, pending := classifyNotRegisteredInstances(nodes, cloudProviderNodeInstances, currentTime)
+	csr.updateUnregisteredNodes(notRegistered)
+	csr.updatePendingNodes(pending)
+	// updateScaleRequests needs to see this cycle's pending nodes so that a node group making
+	// visible progress doesn't time out while its instances are still Creating/Running.
+	csr.updateScaleRequests(currentTime)
+	// Deletion detection has to run before updateReadinessStats, which reads csr.deletedNodes,
+	// so a node detected as deleted this cycle is reflected in this cycle's readiness stats
+	// rather than lagging a cycle behind.
+	csr.updateCloudProviderDeletedNodes(cloudProviderNodeInstances)
+	csr.probeInstanceExistence(currentTime)
+	csr.updateReadinessStats(currentTime)
+	csr.updateIncorrectNodeGroupSizes(currentTime)
+	csr.reconcileOrphans(currentTime)
+
+	return nil
+}
+
+// GetOrphanedNodes returns every node/instance currently classified as orphaned, i.e. whose
+// Kubernetes and cloud provider state has drifted apart.
+func (csr *ClusterStateRegistry) GetOrphanedNodes() []OrphanedNode {
+	csr.Lock()
+	defer csr.Unlock()
+
+	result := make([]OrphanedNode, 0, len(csr.orphanedNodes))
+	for _, orphan := range csr.orphanedNodes {
+		result = append(result, orphan)
+	}
+	return result
+}
+
+// reconcileOrphans classifies nodes/instances whose Kubernetes and cloud provider state has
+// drifted apart and, once they have been in that state for at least
+// config.Reconciliation.GracePeriod, invokes the configured repair action for their class.
+// This runs proactively as part of every UpdateNodes call so that drift is repaired before it
+// can cause spurious scale-up decisions, rather than only surfacing as an incorrect node group
+// size or a deleted-node entry for other callers to notice.
+func (csr *ClusterStateRegistry) reconcileOrphans(currentTime time.Time) {
+	previous := csr.orphanedNodes
+	current := make(map[string]OrphanedNode)
+
+	classify := func(key string, orphan OrphanedNode) {
+		if old, found := previous[key]; found && old.State == orphan.State {
+			orphan.FirstObserved = old.FirstObserved
+		}
+		current[key] = orphan
+	}
+
+	for nodeName := range csr.deletedNodes {
+		nodeGroupID := csr.lastKnownNodeGroup[nodeName]
+		for _, node := range csr.nodes {
+			if node.Name != nodeName {
+				continue
+			}
+			if nodeGroup, err := csr.cloudProvider.NodeGroupForNode(node); err == nil && nodeGroup != nil {
+				nodeGroupID = nodeGroup.Id()
+			}
+		}
+		classify(nodeName, OrphanedNode{
+			NodeName:      nodeName,
+			NodeGroupID:   nodeGroupID,
+			State:         OrphanStateKubernetesOnly,
+			FirstObserved: currentTime,
+		})
+	}
+
+	for _, unregistered := range csr.unregisteredNodes {
+		classify(unregistered.Node.Spec.ProviderID, OrphanedNode{
+			ProviderID:    unregistered.Node.Spec.ProviderID,
+			NodeGroupID:   unregistered.NodeGroup,
+			State:         OrphanStateCloudOnly,
+			FirstObserved: unregistered.UnregisteredSince,
+		})
+	}
+
+	csr.orphanedNodes = current
+
+	gracePeriod := csr.config.Reconciliation.GracePeriod
+	for key, orphan := range csr.orphanedNodes {
+		if currentTime.Sub(orphan.FirstObserved) < gracePeriod {
+			continue
+		}
+		var action ReconciliationAction
+		switch orphan.State {
+		case OrphanStateKubernetesOnly:
+			action = csr.config.Reconciliation.OnKubernetesOnly
+		case OrphanStateCloudOnly:
+			action = csr.config.Reconciliation.OnCloudOnly
+		case OrphanStateDetached:
+			action = csr.config.Reconciliation.OnDetached
+		}
+		metrics.RegisterOrphanedNode(string(orphan.State))
+		if action == nil {
+			continue
+		}
+		if err := action(orphan); err != nil {
+			klog.Warningf("Failed to reconcile orphaned node %v (%v): %v", key, orphan.State, err)
+		}
+	}
+}
+
+// IsClusterHealthy returns true if the number of unready nodes is within the configured bounds.
+func (csr *ClusterStateRegistry) IsClusterHealthy() bool {
+	csr.Lock()
+	defer csr.Unlock()
+
+	totalUnready := len(csr.totalReadiness.Unready) + len(csr.totalReadiness.LongUnregistered)
+	if totalUnready <= csr.config.OkTotalUnreadyCount {
+		return true
+	}
+	totalNodes := len(csr.totalReadiness.Ready) + totalUnready + len(csr.totalReadiness.NotStarted)
+	if totalNodes == 0 {
+		return true
+	}
+	return float64(totalUnready)*100 <= csr.config.MaxTotalUnreadyPercentage*float64(totalNodes)
+}
+
+// IsNodeGroupHealthy returns true if the node group's actual size is close enough to its
+// expected (target) size.
+func (csr *ClusterStateRegistry) IsNodeGroupHealthy(nodeGroupID string) bool {
+	csr.Lock()
+	defer csr.Unlock()
+
+	acceptable, found := csr.acceptableRanges[nodeGroupID]
+	if !found {
+		return true
+	}
+	readiness := csr.perNodeGroupReadiness[nodeGroupID]
+	registered := registeredNodeCount(readiness)
+	return registered >= acceptable.MinNodes && registered <= acceptable.MaxNodes
+}
+
+// IsNodeGroupScalingUp returns true if there is a scale-up in progress for the given node group.
+func (csr *ClusterStateRegistry) IsNodeGroupScalingUp(nodeGroupID string) bool {
+	csr.Lock()
+	defer csr.Unlock()
+
+	_, found := csr.scaleUpRequests[nodeGroupID]
+	return found
+}
+
+// IsNodeGroupSafeToScaleUp returns true if the node group is healthy, has not been disabled by
+// MaxFailedScaleUpsPerNodeGroup, and is not currently backed off due to previous scale-up
+// failures.
+func (csr *ClusterStateRegistry) IsNodeGroupSafeToScaleUp(nodeGroup cloudprovider.NodeGroup, now time.Time) bool {
+	if csr.IsNodeGroupDisabled(nodeGroup) {
+		return false
+	}
+	if !csr.IsNodeGroupHealthy(nodeGroup.Id()) {
+		return false
+	}
+	if csr.backoff.IsBackedOff(nodeGroup, nil, now) {
+		return false
+	}
+	status := csr.GetBackoffStatus(nodeGroup.Id())
+	return !status.Backoff || !status.Until.After(now)
+}
+
+// UpdateScaleDownCandidates records which nodes are currently considered candidates for
+// scale-down, used only for status reporting.
+func (csr *ClusterStateRegistry) UpdateScaleDownCandidates(nodes []*apiv1.Node, now time.Time) {
+	csr.Lock()
+	defer csr.Unlock()
+
+	result := make(map[string][]string)
+	for _, node := range nodes {
+		nodeGroup, err := csr.cloudProvider.NodeGroupForNode(node)
+		if err != nil || nodeGroup == nil {
+			continue
+		}
+		result[nodeGroup.Id()] = append(result[nodeGroup.Id()], node.Name)
+	}
+	csr.candidatesForScaleDown = result
+}
+
+// GetAutoscaledNodesCount returns the current and the target node counts, summed across all
+// autoscaled node groups.
+func (csr *ClusterStateRegistry) GetAutoscaledNodesCount() (current, target int) {
+	csr.Lock()
+	defer csr.Unlock()
+
+	for _, nodeGroup := range csr.cloudProvider.NodeGroups() {
+		readiness := csr.perNodeGroupReadiness[nodeGroup.Id()]
+		current += registeredNodeCount(readiness)
+		targetSize, err := nodeGroup.TargetSize()
+		if err != nil {
+			continue
+		}
+		target += targetSize
+	}
+	return current, target
+}
+
+// GetClusterReadiness returns the overall readiness of the cluster.
+func (csr *ClusterStateRegistry) GetClusterReadiness() Readiness {
+	csr.Lock()
+	defer csr.Unlock()
+	return csr.totalReadiness
+}
+
+// GetUnregisteredNodes returns the list of nodes that the cloud provider reports but that
+// have not yet shown up in Kubernetes.
+func (csr *ClusterStateRegistry) GetUnregisteredNodes() []UnregisteredNode {
+	csr.Lock()
+	defer csr.Unlock()
+
+	result := make([]UnregisteredNode, 0, len(csr.unregisteredNodes))
+	for _, unregistered := range csr.unregisteredNodes {
+		result = append(result, unregistered)
+	}
+	return result
+}
+
+// GetUpcomingNodes returns the number of nodes per node group that are expected to join the
+// cluster due to a pending scale-up or not-yet-ready node, along with the subset of those that
+// have already registered with Kubernetes but are not yet Ready.
+func (csr *ClusterStateRegistry) GetUpcomingNodes() (upcomingCounts map[string]int, upcomingRegistered map[string][]string) {
+	csr.Lock()
+	defer csr.Unlock()
+
+	upcomingCounts = make(map[string]int)
+	upcomingRegistered = make(map[string][]string)
+
+	for _, nodeGroup := range csr.cloudProvider.NodeGroups() {
+		nodeGroupID := nodeGroup.Id()
+		readiness := csr.perNodeGroupReadiness[nodeGroupID]
+		targetSize, err := csr.nodeGroupTargetSize(nodeGroupID)
+		if err != nil {
+			continue
+		}
+		registered := registeredNodeCount(readiness)
+		stillWaitingFor := targetSize - registered - len(readiness.LongUnregistered)
+		upcoming := stillWaitingFor + len(readiness.NotStarted)
+		if upcoming <= 0 {
+			continue
+		}
+		upcomingCounts[nodeGroupID] = upcoming
+		if len(readiness.NotStarted) > 0 {
+			upcomingRegistered[nodeGroupID] = append(upcomingRegistered[nodeGroupID], readiness.NotStarted...)
+		}
+	}
+	return upcomingCounts, upcomingRegistered
+}
+
+func (csr *ClusterStateRegistry) nodeGroupTargetSize(nodeGroupID string) (int, error) {
+	for _, nodeGroup := range csr.cloudProvider.NodeGroups() {
+		if nodeGroup.Id() == nodeGroupID {
+			return nodeGroup.TargetSize()
+		}
+	}
+	return 0, cloudprovider.ErrNotImplemented
+}
+
+// InvalidateNodeInstancesCacheEntry invalidates the cached list of instances for the given
+// node group, forcing the next UpdateNodes call to re-fetch it from the cloud provider.
+func (csr *ClusterStateRegistry) InvalidateNodeInstancesCacheEntry(nodeGroup cloudprovider.NodeGroup) {
+	csr.Lock()
+	defer csr.Unlock()
+	if csr.cloudProviderNodeInstancesCache != nil {
+		delete(csr.cloudProviderNodeInstancesCache, nodeGroup.Id())
+	}
+}
+
+func (csr *ClusterStateRegistry) getCloudProviderNodeInstances() (map[string][]cloudprovider.Instance, error) {
+	if csr.cloudProviderNodeInstancesCache == nil {
+		csr.cloudProviderNodeInstancesCache = make(map[string][]cloudprovider.Instance)
+	}
+	result := make(map[string][]cloudprovider.Instance)
+	for _, nodeGroup := range csr.cloudProvider.NodeGroups() {
+		instances, found := csr.cloudProviderNodeInstancesCache[nodeGroup.Id()]
+		if !found {
+			var err error
+			instances, err = nodeGroup.Nodes()
+			if err != nil {
+				return nil, err
+			}
+			csr.cloudProviderNodeInstancesCache[nodeGroup.Id()] = instances
+		}
+		result[nodeGroup.Id()] = instances
+	}
+	return result, nil
+}
+
+// pendingNodeEntry is the internal, node-group-attributed form of PendingNode kept in
+// ClusterStateRegistry.pendingNodes; GetPendingNodes groups these by node group for callers.
+type pendingNodeEntry struct {
+	nodeGroupID   string
+	providerID    string
+	state         cloudprovider.InstanceState
+	firstObserved time.Time
+}
+
+// classifyNotRegisteredInstances splits cloud provider instances with no matching Kubernetes
+// Node into Pending (the provider reports the instance as Creating or Running - it just hasn't
+// shown up in Kubernetes yet) and Unregistered (the provider has no status for the instance, or
+// reports it Deleting - there is no evidence it is making progress towards joining the cluster).
+func classifyNotRegisteredInstances(nodes []*apiv1.Node, cloudProviderNodeInstances map[string][]cloudprovider.Instance, currentTime time.Time) (unregistered []UnregisteredNode, pending []pendingNodeEntry) {
+	registered := make(map[string]bool)
+	for _, node := range nodes {
+		registered[node.Spec.ProviderID] = true
+		registered[node.Name] = true
+	}
+
+	for nodeGroupID, instances := range cloudProviderNodeInstances {
+		for _, instance := range instances {
+			if registered[instance.Id] {
+				continue
+			}
+			if instance.Status != nil && (instance.Status.State == cloudprovider.InstanceCreating || instance.Status.State == cloudprovider.InstanceRunning) {
+				pending = append(pending, pendingNodeEntry{
+					nodeGroupID:   nodeGroupID,
+					providerID:    instance.Id,
+					state:         instance.Status.State,
+					firstObserved: currentTime,
+				})
+				continue
+			}
+			unregistered = append(unregistered, UnregisteredNode{
+				Node: &apiv1.Node{
+					ObjectMeta: metav1.ObjectMeta{Name: instance.Id},
+					Spec:       apiv1.NodeSpec{ProviderID: instance.Id},
+				},
+				UnregisteredSince: currentTime,
+				NodeGroup:         nodeGroupID,
+			})
+		}
+	}
+	return unregistered, pending
+}
+
+func (csr *ClusterStateRegistry) updateUnregisteredNodes(notRegistered []UnregisteredNode) {
+	previous := csr.unregisteredNodes
+	result := make(map[string]UnregisteredNode)
+	for _, unregistered := range notRegistered {
+		if old, found := previous[unregistered.Node.Name]; found {
+			unregistered.UnregisteredSince = old.UnregisteredSince
+		}
+		result[unregistered.Node.Name] = unregistered
+	}
+	csr.unregisteredNodes = result
+}
+
+func (csr *ClusterStateRegistry) updatePendingNodes(pending []pendingNodeEntry) {
+	previous := csr.pendingNodes
+	result := make(map[string]pendingNodeEntry)
+	for _, entry := range pending {
+		if old, found := previous[entry.providerID]; found {
+			entry.firstObserved = old.firstObserved
+		}
+		result[entry.providerID] = entry
+	}
+	csr.pendingNodes = result
+}
+
+// GetPendingNodes returns, per node group, every cloud provider instance that has been created
+// but has not yet registered as a Kubernetes Node, while the provider still reports it Creating
+// or Running.
+func (csr *ClusterStateRegistry) GetPendingNodes() map[string][]PendingNode {
+	csr.Lock()
+	defer csr.Unlock()
+
+	result := make(map[string][]PendingNode)
+	for _, entry := range csr.pendingNodes {
+		result[entry.nodeGroupID] = append(result[entry.nodeGroupID], PendingNode{
+			ProviderID:    entry.providerID,
+			State:         entry.state,
+			FirstObserved: entry.firstObserved,
+		})
+	}
+	return result
+}
+
+// hasPendingNodesNoLock returns true if at least one cloud provider instance belonging to the
+// given node group is Pending, i.e. created and Creating/Running but not yet a Kubernetes Node.
+func (csr *ClusterStateRegistry) hasPendingNodesNoLock(nodeGroupID string) bool {
+	for _, entry := range csr.pendingNodes {
+		if entry.nodeGroupID == nodeGroupID {
+			return true
+		}
+	}
+	return false
+}
+
+func (csr *ClusterStateRegistry) updateIncorrectNodeGroupSizes(currentTime time.Time) {
+	result := make(map[string]AcceptableRange)
+	incorrect := make(map[string]IncorrectNodeGroupSize)
+
+	for _, nodeGroup := range csr.cloudProvider.NodeGroups() {
+		id := nodeGroup.Id()
+		targetSize, err := nodeGroup.TargetSize()
+		if err != nil {
+			continue
+		}
+		readiness := csr.perNodeGroupReadiness[id]
+		currentSize := registeredNodeCount(readiness)
+
+		// A scale-up request is forgotten by updateScaleRequests the moment it times out, in
+		// the same UpdateNodes cycle that a node group's size is checked here, so the
+		// acceptable minimum can't be keyed off its mere presence. Instead it's widened by
+		// whichever is bigger: the baseline mismatch IsClusterHealthy tolerates cluster-wide,
+		// or - while a scale-up is still in flight and its requested nodes haven't registered
+		// with Kubernetes yet - however many nodes it requested. Likewise, while a
+		// scale-down is in flight, the nodes being deleted are still registered, so the
+		// acceptable maximum is raised by the same baseline, or by however many are pending
+		// deletion from this node group if that's bigger.
+		tolerance := csr.acceptableSizeTolerance(targetSize)
+		minNodes := targetSize - tolerance
+		if scaleUpRequest, found := csr.scaleUpRequests[id]; found && scaleUpRequest.Increase > tolerance {
+			minNodes = targetSize - scaleUpRequest.Increase
+		}
+		maxNodes := targetSize + tolerance
+		scaleDownCount := 0
+		for _, scaleDownRequest := range csr.scaleDownRequests {
+			if scaleDownRequest.NodeGroup.Id() == id {
+				scaleDownCount++
+			}
+		}
+		if scaleDownCount > tolerance {
+			maxNodes = targetSize + scaleDownCount
+		}
+
+		result[id] = AcceptableRange{MinNodes: minNodes, MaxNodes: maxNodes, CurrentTarget: targetSize}
+
+		if currentSize == targetSize {
+			// The node group reached its expected size - its scale-up, if any, is fulfilled and
+			// any backoff accumulated from past scale-up failures no longer applies.
+			delete(csr.scaleUpRequests, id)
+			csr.resetScaleUpBackoffNoLock(id)
+			csr.backoff.RemoveBackoff(nodeGroup, nil)
+		}
+
+		if targetSize == 0 {
+			// The node group was externally scaled down to zero - treat it as reset rather
+			// than keeping it permanently disabled by failures observed before the reset.
+			delete(csr.scaleUpFailures, id)
+			delete(csr.disabledNodeGroups, id)
+		} else if _, found := csr.scaleUpFailures[id]; found {
+			// Re-evaluate against the current time even without a new failure, so a node
+			// group's disablement lifts once every recorded failure ages out of the window.
+			csr.updateNodeGroupDisabledNoLock(id, currentTime)
+		}
+
+		if currentSize != targetSize {
+			if old, found := csr.incorrectNodeGroupSizes[id]; found && old.ExpectedSize == targetSize && old.CurrentSize == currentSize {
+				incorrect[id] = old
+			} else {
+				incorrect[id] = IncorrectNodeGroupSize{
+					ExpectedSize:  targetSize,
+					CurrentSize:   currentSize,
+					FirstObserved: currentTime,
+				}
+			}
+		}
+	}
+
+	csr.acceptableRanges = result
+	csr.incorrectNodeGroupSizes = incorrect
+}
+
+// updateCloudProviderDeletedNodes marks a registered node as deleted once its instance is no
+// longer present in its node group's cached Nodes() listing. NodeGroupForNode returning nil is
+// ambiguous on its own: it means the same thing for a node that was never validly assigned to a
+// tracked node group as it does for a node whose instance the cloud provider just removed. So a
+// nil node group only counts as deleted if the node previously resolved to a real one, tracked
+// via lastKnownNodeGroup; otherwise it is left alone exactly as before.
+//
+// A node already marked deleted - by this cache-based pass on a previous call, or by
+// probeInstanceExistence - stays marked deleted regardless of what this pass observes, as long
+// as it's still registered in Kubernetes: probeInstanceExistence only re-asserts its findings
+// once every InstanceExistenceProbeInterval, and this pass runs on every UpdateNodes call, so
+// without carrying the previous result forward it would erase the probe's result in between.
+//
+// A node carrying the ToBeDeleted taint also counts as deleted when the cloud provider's
+// HasInstance returns ErrNotImplemented for it.
+func (csr *ClusterStateRegistry) updateCloudProviderDeletedNodes(cloudProviderNodeInstances map[string][]cloudprovider.Instance) {
+	present := make(map[string]bool)
+	for _, instances := range cloudProviderNodeInstances {
+		for _, instance := range instances {
+			present[instance.Id] = true
+		}
+	}
+
+	registered := make(map[string]bool, len(csr.nodes))
+	deleted := make(map[string]bool)
+	for name := range csr.deletedNodes {
+		deleted[name] = true
+	}
+	for _, node := range csr.nodes {
+		registered[node.Name] = true
+
+		if deletetaint.HasToBeDeletedTaint(node) {
+			if _, err := csr.cloudProvider.HasInstance(node); err == cloudprovider.ErrNotImplemented {
+				deleted[node.Name] = true
+				continue
+			}
+		}
+
+		nodeGroup, err := csr.cloudProvider.NodeGroupForNode(node)
+		if err != nil {
+			continue
+		}
+		if nodeGroup == nil {
+			if _, wasTracked := csr.lastKnownNodeGroup[node.Name]; wasTracked {
+				deleted[node.Name] = true
+			}
+			continue
+		}
+		csr.lastKnownNodeGroup[node.Name] = nodeGroup.Id()
+
+		id := node.Spec.ProviderID
+		if id == "" {
+			id = node.Name
+		}
+		if !present[id] {
+			deleted[node.Name] = true
+		}
+	}
+	for name := range csr.lastKnownNodeGroup {
+		if !registered[name] {
+			delete(csr.lastKnownNodeGroup, name)
+		}
+	}
+	for name := range deleted {
+		if !registered[name] {
+			delete(deleted, name)
+		}
+	}
+	csr.deletedNodes = deleted
+}
+
+// probeInstanceExistence asks the cloud provider, in a single batched call covering the union
+// of provider ids currently visible in Kubernetes, whether the instance backing every
+// currently registered node still exists, and marks any that are explicitly reported gone as
+// deleted immediately - without waiting for updateCloudProviderDeletedNodes to notice the
+// instance missing from its node group's cached Nodes() listing. A provider id the response
+// omits entirely (e.g. because the provider could only check some of the ids in this batch) is
+// treated as unknown, not deleted, and is left to the cache-based fallback. Runs at most once
+// every config.InstanceExistenceProbeInterval, and is a no-op if that interval is zero or the
+// provider returns ErrNotImplemented, in which case callers fall back to the existing
+// cache-based detection entirely.
+func (csr *ClusterStateRegistry) probeInstanceExistence(currentTime time.Time) {
+	if csr.config.InstanceExistenceProbeInterval <= 0 {
+		return
+	}
+	if !csr.lastInstanceExistenceProbe.IsZero() && currentTime.Sub(csr.lastInstanceExistenceProbe) < csr.config.InstanceExistenceProbeInterval {
+		return
+	}
+	if len(csr.nodes) == 0 {
+		return
+	}
+
+	providerIDs := make([]string, 0, len(csr.nodes))
+	for _, node := range csr.nodes {
+		providerIDs = append(providerIDs, instanceProviderID(node))
+	}
+
+	exists, err := csr.cloudProvider.InstancesExist(providerIDs)
+	if err == cloudprovider.ErrNotImplemented {
+		return
+	}
+	if err != nil {
+		klog.Warningf("Failed to probe instance existence: %v", err)
+		return
+	}
+	csr.lastInstanceExistenceProbe = currentTime
+
+	for _, node := range csr.nodes {
+		stillExists, known := exists[instanceProviderID(node)]
+		if known && !stillExists {
+			csr.deletedNodes[node.Name] = true
+		}
+	}
+}
+
+// instanceProviderID returns the identifier under which a node's backing instance is known to
+// the cloud provider, falling back to the Kubernetes node name for nodes that haven't reported
+// a providerID yet.
+func instanceProviderID(node *apiv1.Node) string {
+	if node.Spec.ProviderID != "" {
+		return node.Spec.ProviderID
+	}
+	return node.Name
+}
+
+// updateReadinessStats recomputes per-node-group and cluster-wide readiness from the most
+// recently observed node list.
+func (csr *ClusterStateRegistry) updateReadinessStats(currentTime time.Time) {
+	perNodeGroup := make(map[string]Readiness)
+	total := Readiness{}
+
+	update := func(id string, classify func(r *Readiness)) {
+		r := perNodeGroup[id]
+		classify(&r)
+		perNodeGroup[id] = r
+	}
+	recordBreakdown := func(breakdown *map[string]ClassifierVerdicts, nodeName string, perClassifier map[string]NodeReadinessState) {
+		for name, state := range perClassifier {
+			if state == NodeReady {
+				continue
+			}
+			if *breakdown == nil {
+				*breakdown = make(map[string]ClassifierVerdicts)
+			}
+			verdicts := (*breakdown)[name]
+			if state == NodeNotStarted {
+				verdicts.NotStarted = append(verdicts.NotStarted, nodeName)
+			} else {
+				verdicts.Unready = append(verdicts.Unready, nodeName)
+			}
+			(*breakdown)[name] = verdicts
+		}
+	}
+
+	for _, node := range csr.nodes {
+		nodeGroup, err := csr.cloudProvider.NodeGroupForNode(node)
+		ngID := ""
+		if err == nil && nodeGroup != nil {
+			ngID = nodeGroup.Id()
+		}
+
+		state, perClassifier := csr.readinessClassifier.Classify(node, currentTime)
+		if state == NodeReady && !csr.IsNodeUsableForScheduling(node, currentTime) {
+			// The node is Ready, but has been under sustained pressure for longer than the
+			// grace period - don't let the rest of the autoscaler rely on it as if it were
+			// healthy.
+			state = NodeUnready
+		}
+		switch {
+		case csr.deletedNodes[node.Name]:
+			update(ngID, func(r *Readiness) { r.Deleted = append(r.Deleted, node.Name) })
+			total.Deleted = append(total.Deleted, node.Name)
+		case deletetaint.HasToBeDeletedTaint(node):
+			// Nodes that are being drained for deletion are neither ready nor unready - they
+			// are already accounted for by the scale-down path. They're still registered
+			// nodes though, so they get their own bucket rather than being dropped entirely.
+			update(ngID, func(r *Readiness) { r.ToBeDeleted = append(r.ToBeDeleted, node.Name) })
+			total.ToBeDeleted = append(total.ToBeDeleted, node.Name)
+		case state == NodeReady:
+			update(ngID, func(r *Readiness) { r.Ready = append(r.Ready, node.Name) })
+			total.Ready = append(total.Ready, node.Name)
+		case state == NodeNotStarted:
+			update(ngID, func(r *Readiness) { r.NotStarted = append(r.NotStarted, node.Name) })
+			total.NotStarted = append(total.NotStarted, node.Name)
+			recordBreakdown(&total.Breakdown, node.Name, perClassifier)
+		default:
+			update(ngID, func(r *Readiness) { r.Unready = append(r.Unready, node.Name) })
+			total.Unready = append(total.Unready, node.Name)
+			recordBreakdown(&total.Breakdown, node.Name, perClassifier)
+		}
+
+		for _, condition := range node.Status.Conditions {
+			if condition.Status != apiv1.ConditionTrue {
+				continue
+			}
+			update(ngID, func(r *Readiness) { appendPressureCondition(r, condition.Type, node.Name) })
+			appendPressureCondition(&total, condition.Type, node.Name)
+		}
+	}
+
+	for name, unregistered := range csr.unregisteredNodes {
+		if csr.config.MaxNodeProvisionTime > 0 && currentTime.Sub(unregistered.UnregisteredSince) > csr.config.MaxNodeProvisionTime {
+			total.LongUnregistered = append(total.LongUnregistered, name)
+			update(unregistered.NodeGroup, func(r *Readiness) { r.LongUnregistered = append(r.LongUnregistered, name) })
+		} else {
+			total.Unregistered = append(total.Unregistered, name)
+			update(unregistered.NodeGroup, func(r *Readiness) { r.Unregistered = append(r.Unregistered, name) })
+		}
+	}
+
+	csr.perNodeGroupReadiness = perNodeGroup
+	csr.totalReadiness = total
+}
+
+// registeredNodeCount returns the number of nodes in r that are registered with Kubernetes,
+// including those already tainted for deletion as part of an in-flight scale-down.
+func registeredNodeCount(r Readiness) int {
+	return len(r.Ready) + len(r.Unready) + len(r.NotStarted) + len(r.ToBeDeleted)
+}
+
+// acceptableSizeTolerance returns the node-count mismatch a node group with the given target
+// size is allowed before its size is considered incorrect, mirroring the
+// OkTotalUnreadyCount/MaxTotalUnreadyPercentage tolerance IsClusterHealthy applies cluster-wide.
+func (csr *ClusterStateRegistry) acceptableSizeTolerance(targetSize int) int {
+	tolerance := csr.config.OkTotalUnreadyCount
+	if pct := int(csr.config.MaxTotalUnreadyPercentage * float64(targetSize) / 100); pct > tolerance {
+		tolerance = pct
+	}
+	return tolerance
+}
+
+// appendPressureCondition records nodeName against the Readiness counter matching conditionType,
+// and is a no-op for any other condition type.
+func appendPressureCondition(r *Readiness, conditionType apiv1.NodeConditionType, nodeName string) {
+	switch conditionType {
+	case apiv1.NodeNetworkUnavailable:
+		r.NetworkUnavailable = append(r.NetworkUnavailable, nodeName)
+	case apiv1.NodeDiskPressure:
+		r.DiskPressure = append(r.DiskPressure, nodeName)
+	case apiv1.NodeMemoryPressure:
+		r.MemoryPressure = append(r.MemoryPressure, nodeName)
+	case apiv1.NodePIDPressure:
+		r.PIDPressure = append(r.PIDPressure, nodeName)
+	}
+}
+
+// IsNodeUsableForScheduling returns false if the node has been reporting any of
+// NetworkUnavailable, DiskPressure, MemoryPressure or PIDPressure as true for longer than
+// NodePressureGracePeriod, even though its NodeReady condition may still be true. It doesn't
+// touch any ClusterStateRegistry state and can be called without holding its lock.
+func (csr *ClusterStateRegistry) IsNodeUsableForScheduling(node *apiv1.Node, currentTime time.Time) bool {
+	gracePeriod := csr.config.NodePressureGracePeriod
+	if gracePeriod <= 0 {
+		gracePeriod = defaultNodePressureGracePeriod
+	}
+
+	for _, condition := range node.Status.Conditions {
+		if condition.Status != apiv1.ConditionTrue {
+			continue
+		}
+		for _, pressureType := range pressureConditionTypes {
+			if condition.Type == pressureType && currentTime.Sub(condition.LastTransitionTime.Time) > gracePeriod {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// GetStatus builds the ClusterAutoscalerStatus describing the health of the cluster and of
+// every node group at currentTime, carrying over LastTransitionTime from the last reported
+// status for conditions whose status hasn't actually changed.
+func (csr *ClusterStateRegistry) GetStatus(currentTime time.Time) *api.ClusterAutoscalerStatus {
+	csr.Lock()
+	defer csr.Unlock()
+
+	result := &api.ClusterAutoscalerStatus{
+		ClusterwideConditions: csr.getClusterwideConditions(currentTime),
+	}
+	for _, nodeGroup := range csr.cloudProvider.NodeGroups() {
+		result.NodeGroupStatuses = append(result.NodeGroupStatuses, api.NodeGroupStatus{
+			ProviderID: nodeGroup.Id(),
+			Conditions: csr.getNodeGroupConditions(nodeGroup.Id(), currentTime),
+		})
+	}
+
+	updateLastTransition(csr.lastStatus, result)
+	csr.lastStatus = result
+	return result
+}
+
+func (csr *ClusterStateRegistry) getClusterwideConditions(currentTime time.Time) []api.ClusterAutoscalerCondition {
+	now := metav1.Time{Time: currentTime}
+	healthStatus := api.ClusterAutoscalerHealthy
+	if !csr.isClusterHealthyNoLock() {
+		healthStatus = api.ClusterAutoscalerUnhealthy
+	}
+
+	scaleUpStatus := api.ClusterAutoscalerNoActivity
+	if len(csr.scaleUpRequests) > 0 {
+		scaleUpStatus = api.ClusterAutoscalerInProgress
+	}
+
+	scaleDownStatus := api.ClusterAutoscalerNoCandidates
+	if len(csr.candidatesForScaleDown) > 0 {
+		scaleDownStatus = api.ClusterAutoscalerCandidatesPresent
+	}
+
+	return []api.ClusterAutoscalerCondition{
+		{Type: api.ClusterAutoscalerHealth, Status: healthStatus, LastProbeTime: now},
+		{Type: api.ClusterAutoscalerScaleUp, Status: scaleUpStatus, LastProbeTime: now},
+		{Type: api.ClusterAutoscalerScaleDown, Status: scaleDownStatus, LastProbeTime: now},
+	}
+}
+
+func (csr *ClusterStateRegistry) getNodeGroupConditions(nodeGroupID string, currentTime time.Time) []api.ClusterAutoscalerCondition {
+	now := metav1.Time{Time: currentTime}
+	healthStatus := api.ClusterAutoscalerHealthy
+	if !csr.isNodeGroupHealthyNoLock(nodeGroupID) {
+		healthStatus = api.ClusterAutoscalerUnhealthy
+	}
+
+	scaleUpStatus := api.ClusterAutoscalerNoActivity
+	if _, found := csr.scaleUpRequests[nodeGroupID]; found {
+		scaleUpStatus = api.ClusterAutoscalerInProgress
+	}
+
+	scaleDownStatus := api.ClusterAutoscalerNoCandidates
+	if len(csr.candidatesForScaleDown[nodeGroupID]) > 0 {
+		scaleDownStatus = api.ClusterAutoscalerCandidatesPresent
+	}
+
+	backoffStatus := api.ClusterAutoscalerNotBackedOff
+	for key, entry := range csr.scaleUpBackoffs {
+		if key.nodeGroupID == nodeGroupID && entry.until.After(currentTime) {
+			backoffStatus = api.ClusterAutoscalerBackedOff
+			break
+		}
+	}
+
+	disabledStatus := api.ClusterAutoscalerEnabled
+	if csr.disabledNodeGroups[nodeGroupID] {
+		disabledStatus = api.ClusterAutoscalerDisabled
+	}
+
+	pressureStatus, pressureMessage := csr.nodeGroupPressureStatusNoLock(nodeGroupID)
+
+	return []api.ClusterAutoscalerCondition{
+		{Type: api.ClusterAutoscalerHealth, Status: healthStatus, LastProbeTime: now},
+		{Type: api.ClusterAutoscalerScaleUp, Status: scaleUpStatus, LastProbeTime: now},
+		{Type: api.ClusterAutoscalerScaleDown, Status: scaleDownStatus, LastProbeTime: now},
+		{Type: api.ClusterAutoscalerBackoff, Status: backoffStatus, LastProbeTime: now},
+		{Type: api.ClusterAutoscalerNodeGroupDisabled, Status: disabledStatus, LastProbeTime: now},
+		{Type: api.ClusterAutoscalerNodePressure, Status: pressureStatus, LastProbeTime: now, Message: pressureMessage},
+	}
+}
+
+// nodeGroupPressureStatusNoLock reports whether any node in the node group currently has a
+// NetworkUnavailable, DiskPressure, MemoryPressure or PIDPressure condition true, and if so a
+// human-readable message naming which ones.
+func (csr *ClusterStateRegistry) nodeGroupPressureStatusNoLock(nodeGroupID string) (api.ClusterAutoscalerConditionStatus, string) {
+	readiness := csr.perNodeGroupReadiness[nodeGroupID]
+	var kinds []string
+	if len(readiness.NetworkUnavailable) > 0 {
+		kinds = append(kinds, "NetworkUnavailable")
+	}
+	if len(readiness.DiskPressure) > 0 {
+		kinds = append(kinds, "DiskPressure")
+	}
+	if len(readiness.MemoryPressure) > 0 {
+		kinds = append(kinds, "MemoryPressure")
+	}
+	if len(readiness.PIDPressure) > 0 {
+		kinds = append(kinds, "PIDPressure")
+	}
+	if len(kinds) == 0 {
+		return api.ClusterAutoscalerNoPressure, ""
+	}
+	return api.ClusterAutoscalerPressureDetected, strings.Join(kinds, ",")
+}
+
+func (csr *ClusterStateRegistry) isClusterHealthyNoLock() bool {
+	totalUnready := len(csr.totalReadiness.Unready) + len(csr.totalReadiness.LongUnregistered)
+	if totalUnready <= csr.config.OkTotalUnreadyCount {
+		return true
+	}
+	totalNodes := len(csr.totalReadiness.Ready) + totalUnready + len(csr.totalReadiness.NotStarted)
+	if totalNodes == 0 {
+		return true
+	}
+	return float64(totalUnready)*100 <= csr.config.MaxTotalUnreadyPercentage*float64(totalNodes)
+}
+
+func (csr *ClusterStateRegistry) isNodeGroupHealthyNoLock(nodeGroupID string) bool {
+	acceptable, found := csr.acceptableRanges[nodeGroupID]
+	if !found {
+		return true
+	}
+	readiness := csr.perNodeGroupReadiness[nodeGroupID]
+	registered := registeredNodeCount(readiness)
+	return registered >= acceptable.MinNodes && registered <= acceptable.MaxNodes
+}
+
+// updateLastTransition copies LastTransitionTime from oldStatus into newStatus for every
+// condition whose Status hasn't changed, and sets it to the condition's LastProbeTime
+// otherwise (or if there is no corresponding condition in oldStatus).
+func updateLastTransition(oldStatus, newStatus *api.ClusterAutoscalerStatus) {
+	var oldClusterwideConditions []api.ClusterAutoscalerCondition
+	oldByID := make(map[string][]api.ClusterAutoscalerCondition)
+	if oldStatus != nil {
+		oldClusterwideConditions = oldStatus.ClusterwideConditions
+		for _, ng := range oldStatus.NodeGroupStatuses {
+			oldByID[ng.ProviderID] = ng.Conditions
+		}
+	}
+
+	newStatus.ClusterwideConditions = patchLastTransitionTime(oldClusterwideConditions, newStatus.ClusterwideConditions)
+	for i, ng := range newStatus.NodeGroupStatuses {
+		newStatus.NodeGroupStatuses[i].Conditions = patchLastTransitionTime(oldByID[ng.ProviderID], ng.Conditions)
+	}
+}
+
+// patchLastTransitionTime returns a freshly allocated copy of newConditions with
+// LastTransitionTime filled in: carried over from oldConditions for any condition whose Status
+// hasn't changed, or set to the condition's own LastProbeTime otherwise. It never modifies
+// either input slice, since both may be aliased by other callers (e.g. a NodeGroupStatus sharing
+// its Conditions slice with ClusterwideConditions).
+func patchLastTransitionTime(oldConditions, newConditions []api.ClusterAutoscalerCondition) []api.ClusterAutoscalerCondition {
+	oldByType := make(map[api.ClusterAutoscalerConditionType]api.ClusterAutoscalerCondition)
+	for _, cond := range oldConditions {
+		oldByType[cond.Type] = cond
+	}
+	patched := make([]api.ClusterAutoscalerCondition, len(newConditions))
+	for i, cond := range newConditions {
+		if old, found := oldByType[cond.Type]; found && old.Status == cond.Status {
+			cond.LastTransitionTime = old.LastTransitionTime
+		} else {
+			cond.LastTransitionTime = cond.LastProbeTime
+		}
+		patched[i] = cond
+	}
+	return patched
+}
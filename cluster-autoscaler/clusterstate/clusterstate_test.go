@@ -577,6 +577,91 @@ func TestTaintBasedNodeDeletion(t *testing.T) {
 	assert.Empty(t, upcomingRegistered["ng1"]) // Only unregistered.
 }
 
+func TestInstanceExistenceProbe(t *testing.T) {
+	now := time.Now()
+	ng1_1 := BuildTestNode("ng1-1", 1000, 1000)
+	SetNodeReadyState(ng1_1, true, now.Add(-time.Minute))
+	ng1_1.Spec.ProviderID = "ng1-1"
+	ng1_2 := BuildTestNode("ng1-2", 1000, 1000)
+	SetNodeReadyState(ng1_2, true, now.Add(-time.Minute))
+	ng1_2.Spec.ProviderID = "ng1-2"
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 2)
+	provider.AddNode("ng1", ng1_1)
+	provider.AddNode("ng1", ng1_2)
+	provider.SetInstancesExist(func(providerIDs []string) (map[string]bool, error) {
+		result := make(map[string]bool)
+		for _, id := range providerIDs {
+			result[id] = id != "ng1-2"
+		}
+		return result, nil
+	})
+
+	fakeClient := &fake.Clientset{}
+	fakeLogRecorder, _ := utils.NewStatusMapRecorder(fakeClient, "kube-system", kube_record.NewFakeRecorder(5), false, "my-cool-configmap")
+	clusterstate := NewClusterStateRegistry(provider, ClusterStateRegistryConfig{
+		MaxTotalUnreadyPercentage:      10,
+		OkTotalUnreadyCount:            1,
+		InstanceExistenceProbeInterval: time.Minute,
+	}, fakeLogRecorder, newBackoff())
+
+	// Unlike TestCloudProviderDeletedNodes, no InvalidateNodeInstancesCacheEntry call is needed -
+	// the probe notices the missing instance on the very next UpdateNodes call.
+	err := clusterstate.UpdateNodes([]*apiv1.Node{ng1_1, ng1_2}, nil, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(GetCloudProviderDeletedNodeNames(clusterstate)))
+	assert.Equal(t, "ng1-2", GetCloudProviderDeletedNodeNames(clusterstate)[0])
+
+	// A second call within InstanceExistenceProbeInterval does not re-probe, but the prior
+	// result is sticky until the node is removed from Kubernetes.
+	err = clusterstate.UpdateNodes([]*apiv1.Node{ng1_1, ng1_2}, nil, now.Add(10*time.Second))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(GetCloudProviderDeletedNodeNames(clusterstate)))
+}
+
+func TestInstanceExistenceProbePartialFailure(t *testing.T) {
+	now := time.Now()
+	ng1_1 := BuildTestNode("ng1-1", 1000, 1000)
+	SetNodeReadyState(ng1_1, true, now.Add(-time.Minute))
+	ng1_1.Spec.ProviderID = "ng1-1"
+	ng1_2 := BuildTestNode("ng1-2", 1000, 1000)
+	SetNodeReadyState(ng1_2, true, now.Add(-time.Minute))
+	ng1_2.Spec.ProviderID = "ng1-2"
+	// Not part of any node group - the probe should still cover it.
+	noNgNode := BuildTestNode("no-ng", 1000, 1000)
+	SetNodeReadyState(noNgNode, true, now.Add(-time.Minute))
+	noNgNode.Spec.ProviderID = "no-ng"
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 2)
+	provider.AddNode("ng1", ng1_1)
+	provider.AddNode("ng1", ng1_2)
+	provider.AddNode("no_ng", noNgNode)
+	provider.SetInstancesExist(func(providerIDs []string) (map[string]bool, error) {
+		// The provider could only check ng1-2 and no-ng this round; ng1-1 is omitted
+		// entirely, as if that particular lookup failed, and must not be treated as deleted.
+		return map[string]bool{
+			"ng1-2": false,
+			"no-ng": true,
+		}, nil
+	})
+
+	fakeClient := &fake.Clientset{}
+	fakeLogRecorder, _ := utils.NewStatusMapRecorder(fakeClient, "kube-system", kube_record.NewFakeRecorder(5), false, "my-cool-configmap")
+	clusterstate := NewClusterStateRegistry(provider, ClusterStateRegistryConfig{
+		MaxTotalUnreadyPercentage:      10,
+		OkTotalUnreadyCount:            1,
+		InstanceExistenceProbeInterval: time.Minute,
+	}, fakeLogRecorder, newBackoff())
+
+	err := clusterstate.UpdateNodes([]*apiv1.Node{ng1_1, ng1_2, noNgNode}, nil, now)
+	assert.NoError(t, err)
+	deleted := GetCloudProviderDeletedNodeNames(clusterstate)
+	assert.Equal(t, 1, len(deleted))
+	assert.Equal(t, "ng1-2", deleted[0])
+}
+
 func TestIncorrectSize(t *testing.T) {
 	ng1_1 := BuildTestNode("ng1-1", 1000, 1000)
 	provider := testprovider.NewTestCloudProvider(nil, nil)
@@ -650,6 +735,50 @@ func TestUnregisteredNodes(t *testing.T) {
 	assert.Equal(t, 0, len(clusterstate.GetUnregisteredNodes()))
 }
 
+func TestPendingNodes(t *testing.T) {
+	now := time.Now()
+
+	ng1_1 := BuildTestNode("ng1-1", 1000, 1000)
+	ng1_1.Spec.ProviderID = "ng1-1"
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 2)
+	provider.AddNode("ng1", ng1_1)
+	// ng1-2 has been created by the provider but hasn't registered as a Kubernetes Node yet.
+	provider.AddPendingInstance("ng1", "ng1-2", cloudprovider.InstanceCreating)
+	ng1 := provider.GetNodeGroup("ng1")
+
+	fakeClient := &fake.Clientset{}
+	fakeLogRecorder, _ := utils.NewStatusMapRecorder(fakeClient, "kube-system", kube_record.NewFakeRecorder(5), false, "my-cool-configmap")
+	clusterstate := NewClusterStateRegistry(provider, ClusterStateRegistryConfig{
+		MaxTotalUnreadyPercentage: 10,
+		OkTotalUnreadyCount:       1,
+		MaxNodeProvisionTime:      10 * time.Second,
+	}, fakeLogRecorder, newBackoff())
+	clusterstate.RegisterOrUpdateScaleUp(ng1, 1, now)
+
+	err := clusterstate.UpdateNodes([]*apiv1.Node{ng1_1}, nil, now)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(clusterstate.GetUnregisteredNodes()))
+	pending := clusterstate.GetPendingNodes()
+	assert.Equal(t, 1, len(pending["ng1"]))
+	assert.Equal(t, "ng1-2", pending["ng1"][0].ProviderID)
+	assert.Equal(t, cloudprovider.InstanceCreating, pending["ng1"][0].State)
+
+	// Long after MaxNodeProvisionTime, the scale-up would normally have timed out - but ng1-2
+	// is still Pending, so the provisioning timer keeps getting reset and no failure is recorded.
+	err = clusterstate.UpdateNodes([]*apiv1.Node{ng1_1}, nil, now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Empty(t, clusterstate.GetScaleUpFailures())
+	assert.Equal(t, 1, len(clusterstate.GetPendingNodes()["ng1"]))
+
+	// Once ng1-2 registers as a Kubernetes Node, it is no longer Pending.
+	ng1_2 := BuildTestNode("ng1-2", 1000, 1000)
+	ng1_2.Spec.ProviderID = "ng1-2"
+	err = clusterstate.UpdateNodes([]*apiv1.Node{ng1_1, ng1_2}, nil, now.Add(time.Minute))
+	assert.NoError(t, err)
+	assert.Empty(t, clusterstate.GetPendingNodes()["ng1"])
+}
+
 func TestCloudProviderDeletedNodes(t *testing.T) {
 	now := time.Now()
 	ng1_1 := BuildTestNode("ng1-1", 1000, 1000)
@@ -1050,6 +1179,307 @@ func TestScaleUpFailures(t *testing.T) {
 	assert.Empty(t, clusterstate.GetScaleUpFailures())
 }
 
+func TestNodeGroupDisabledByFailureThreshold(t *testing.T) {
+	now := time.Now()
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 0, 10, 3)
+	ng1 := provider.GetNodeGroup("ng1")
+
+	fakeClient := &fake.Clientset{}
+	fakeLogRecorder, _ := utils.NewStatusMapRecorder(fakeClient, "kube-system", kube_record.NewFakeRecorder(5), false, "my-cool-configmap")
+	clusterstate := NewClusterStateRegistry(provider, ClusterStateRegistryConfig{
+		MaxFailedScaleUpsPerNodeGroup: 2,
+		FailureCountingWindow:         time.Hour,
+	}, fakeLogRecorder, newBackoff())
+
+	assert.False(t, clusterstate.IsNodeGroupDisabled(ng1))
+
+	// Two failures (of different reasons) are still within the threshold.
+	clusterstate.RegisterFailedScaleUp(ng1, metrics.Timeout, now)
+	clusterstate.RegisterFailedScaleUp(ng1, metrics.APIError, now)
+	assert.False(t, clusterstate.IsNodeGroupDisabled(ng1))
+
+	// The third failure within the window trips the threshold - this should be visible
+	// immediately, without waiting for the next UpdateNodes cycle.
+	clusterstate.RegisterFailedScaleUp(ng1, metrics.QuotaExceeded, now)
+	assert.True(t, clusterstate.IsNodeGroupDisabled(ng1))
+	assert.False(t, clusterstate.IsNodeGroupSafeToScaleUp(ng1, now))
+
+	// Once every recorded failure ages out of the window, the node group is re-enabled on
+	// the next pass that re-evaluates it, even without a new failure.
+	err := clusterstate.UpdateNodes([]*apiv1.Node{}, nil, now.Add(2*time.Hour))
+	assert.NoError(t, err)
+	assert.False(t, clusterstate.IsNodeGroupDisabled(ng1))
+
+	// Tripping the threshold again and then manually clearing it lifts the disablement
+	// immediately, same as ResetBackoff does for exponential backoff.
+	clusterstate.RegisterFailedScaleUp(ng1, metrics.Timeout, now.Add(2*time.Hour))
+	clusterstate.RegisterFailedScaleUp(ng1, metrics.Timeout, now.Add(2*time.Hour))
+	clusterstate.RegisterFailedScaleUp(ng1, metrics.Timeout, now.Add(2*time.Hour))
+	assert.True(t, clusterstate.IsNodeGroupDisabled(ng1))
+	clusterstate.ClearNodeGroupFailures(ng1)
+	assert.False(t, clusterstate.IsNodeGroupDisabled(ng1))
+	assert.Empty(t, clusterstate.GetScaleUpFailures())
+
+	// Externally scaling the node group's target size down to zero also clears the
+	// disablement, the same way it resets exponential backoff.
+	clusterstate.RegisterFailedScaleUp(ng1, metrics.Timeout, now.Add(2*time.Hour))
+	clusterstate.RegisterFailedScaleUp(ng1, metrics.Timeout, now.Add(2*time.Hour))
+	clusterstate.RegisterFailedScaleUp(ng1, metrics.Timeout, now.Add(2*time.Hour))
+	assert.True(t, clusterstate.IsNodeGroupDisabled(ng1))
+	ng1.(*testprovider.TestNodeGroup).SetTargetSize(0)
+	err = clusterstate.UpdateNodes([]*apiv1.Node{}, nil, now.Add(2*time.Hour))
+	assert.NoError(t, err)
+	assert.False(t, clusterstate.IsNodeGroupDisabled(ng1))
+}
+
+func TestOrphanedNodeReconciliation(t *testing.T) {
+	now := time.Now()
+
+	ng1_1 := BuildTestNode("ng1-1", 1000, 1000)
+	SetNodeReadyState(ng1_1, true, now.Add(-time.Minute))
+	ng1_1.Spec.ProviderID = "ng1-1"
+	ng1_2 := BuildTestNode("ng1-2", 1000, 1000)
+	SetNodeReadyState(ng1_2, true, now.Add(-time.Minute))
+	ng1_2.Spec.ProviderID = "ng1-2"
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 2)
+	provider.AddNode("ng1", ng1_1)
+	provider.AddNode("ng1", ng1_2)
+
+	var repaired []string
+	fakeClient := &fake.Clientset{}
+	fakeLogRecorder, _ := utils.NewStatusMapRecorder(fakeClient, "kube-system", kube_record.NewFakeRecorder(5), false, "my-cool-configmap")
+	clusterstate := NewClusterStateRegistry(provider, ClusterStateRegistryConfig{
+		MaxTotalUnreadyPercentage: 10,
+		OkTotalUnreadyCount:       1,
+		Reconciliation: ReconciliationConfig{
+			GracePeriod: time.Minute,
+			OnKubernetesOnly: func(orphan OrphanedNode) error {
+				repaired = append(repaired, orphan.NodeName)
+				return nil
+			},
+		},
+	}, fakeLogRecorder, newBackoff())
+
+	// Both nodes are registered correctly - nothing orphaned yet.
+	err := clusterstate.UpdateNodes([]*apiv1.Node{ng1_1, ng1_2}, nil, now)
+	assert.NoError(t, err)
+	assert.Empty(t, clusterstate.GetOrphanedNodes())
+	assert.Empty(t, repaired)
+
+	// The instance backing ng1-2 disappears from the cloud provider.
+	nodeGroup, err := provider.NodeGroupForNode(ng1_2)
+	assert.NoError(t, err)
+	provider.DeleteNode(ng1_2)
+	clusterstate.InvalidateNodeInstancesCacheEntry(nodeGroup)
+
+	err = clusterstate.UpdateNodes([]*apiv1.Node{ng1_1, ng1_2}, nil, now)
+	assert.NoError(t, err)
+	orphans := clusterstate.GetOrphanedNodes()
+	assert.Equal(t, 1, len(orphans))
+	assert.Equal(t, OrphanStateKubernetesOnly, orphans[0].State)
+	// Grace period hasn't elapsed yet, so no repair action should have run.
+	assert.Empty(t, repaired)
+
+	// Once the grace period elapses the repair action is invoked exactly once for the node.
+	err = clusterstate.UpdateNodes([]*apiv1.Node{ng1_1, ng1_2}, nil, now.Add(2*time.Minute))
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"ng1-2"}, repaired)
+}
+
+func TestPerNodeGroupScaleUpBackoff(t *testing.T) {
+	now := time.Now()
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 0, 10, 0)
+	ng1 := provider.GetNodeGroup("ng1")
+
+	fakeClient := &fake.Clientset{}
+	fakeLogRecorder, _ := utils.NewStatusMapRecorder(fakeClient, "kube-system", kube_record.NewFakeRecorder(5), false, "my-cool-configmap")
+	clusterstate := NewClusterStateRegistry(provider, ClusterStateRegistryConfig{}, fakeLogRecorder, newBackoff())
+
+	assert.False(t, clusterstate.GetBackoffStatus("ng1").Backoff)
+
+	clusterstate.RegisterFailedScaleUp(ng1, metrics.Timeout, now)
+	status := clusterstate.GetBackoffStatus("ng1")
+	assert.True(t, status.Backoff)
+	assert.Equal(t, 1, status.Attempt)
+	assert.Equal(t, metrics.Timeout, status.Reason)
+	firstUntil := status.Until
+
+	// A second consecutive failure of the same reason should back off for longer.
+	clusterstate.RegisterFailedScaleUp(ng1, metrics.Timeout, now)
+	status = clusterstate.GetBackoffStatus("ng1")
+	assert.Equal(t, 2, status.Attempt)
+	assert.True(t, status.Until.After(firstUntil))
+
+	// Manually clearing the backoff removes it immediately.
+	clusterstate.ResetBackoff("ng1")
+	assert.False(t, clusterstate.GetBackoffStatus("ng1").Backoff)
+}
+
+// gpuDriverClassifier is a NodeReadinessClassifier standing in for an operator's custom boot
+// sequence check: a node is NotStarted until it carries the "gpu-driver-ready" label, even if
+// its NodeReady condition is already true.
+type gpuDriverClassifier struct{}
+
+func (gpuDriverClassifier) Name() string {
+	return "GPUDriver"
+}
+
+func (gpuDriverClassifier) ClassifyNode(node *apiv1.Node, currentTime time.Time) (NodeReadinessState, bool) {
+	if _, hasGPU := node.Labels["gpu"]; !hasGPU {
+		return NodeReady, false
+	}
+	if node.Labels["gpu-driver-ready"] != "true" {
+		return NodeNotStarted, true
+	}
+	return NodeReady, true
+}
+
+func TestCustomReadinessClassifier(t *testing.T) {
+	now := time.Now()
+
+	ng1_1 := BuildTestNode("ng1-1", 1000, 1000)
+	SetNodeReadyState(ng1_1, true, now.Add(-time.Minute))
+	ng1_1.Labels = map[string]string{"gpu": "true"}
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	provider.AddNode("ng1", ng1_1)
+
+	fakeClient := &fake.Clientset{}
+	fakeLogRecorder, _ := utils.NewStatusMapRecorder(fakeClient, "kube-system", kube_record.NewFakeRecorder(5), false, "some-map")
+	clusterstate := NewClusterStateRegistry(provider, ClusterStateRegistryConfig{
+		MaxTotalUnreadyPercentage: 10,
+		OkTotalUnreadyCount:       1,
+		ReadinessClassifiers:      []NodeReadinessClassifier{gpuDriverClassifier{}},
+	}, fakeLogRecorder, newBackoff())
+
+	// NodeReady is true, but the GPU driver hasn't reported ready yet - the node should still
+	// count as NotStarted, and the breakdown should attribute it to the GPUDriver classifier.
+	err := clusterstate.UpdateNodes([]*apiv1.Node{ng1_1}, nil, now)
+	assert.NoError(t, err)
+	readiness := clusterstate.GetClusterReadiness()
+	assert.Equal(t, 1, len(readiness.NotStarted))
+	assert.Equal(t, 0, len(readiness.Ready))
+	assert.Equal(t, []string{"ng1-1"}, readiness.Breakdown["GPUDriver"].NotStarted)
+
+	// Once the driver reports ready, the node is Ready and no longer appears in any breakdown.
+	ng1_1.Labels["gpu-driver-ready"] = "true"
+	err = clusterstate.UpdateNodes([]*apiv1.Node{ng1_1}, nil, now)
+	assert.NoError(t, err)
+	readiness = clusterstate.GetClusterReadiness()
+	assert.Equal(t, 0, len(readiness.NotStarted))
+	assert.Equal(t, 1, len(readiness.Ready))
+	assert.Empty(t, readiness.Breakdown["GPUDriver"].NotStarted)
+}
+
+func TestNodePressureReadiness(t *testing.T) {
+	now := time.Now()
+
+	ng1_1 := BuildTestNode("ng1-1", 1000, 1000)
+	SetNodeReadyState(ng1_1, true, now.Add(-time.Hour))
+
+	provider := testprovider.NewTestCloudProvider(nil, nil)
+	provider.AddNodeGroup("ng1", 1, 10, 1)
+	provider.AddNode("ng1", ng1_1)
+
+	fakeClient := &fake.Clientset{}
+	fakeLogRecorder, _ := utils.NewStatusMapRecorder(fakeClient, "kube-system", kube_record.NewFakeRecorder(5), false, "some-map")
+	clusterstate := NewClusterStateRegistry(provider, ClusterStateRegistryConfig{
+		MaxTotalUnreadyPercentage: 10,
+		OkTotalUnreadyCount:       0,
+		NodePressureGracePeriod:   time.Minute,
+	}, fakeLogRecorder, newBackoff())
+
+	// DiskPressure just started - still within the grace period, so the node still counts as
+	// Ready, but is already reflected in the DiskPressure counter.
+	SetNodeCondition(ng1_1, apiv1.NodeDiskPressure, apiv1.ConditionTrue, now.Add(-30*time.Second))
+	err := clusterstate.UpdateNodes([]*apiv1.Node{ng1_1}, nil, now)
+	assert.NoError(t, err)
+	readiness := clusterstate.GetClusterReadiness()
+	assert.Equal(t, []string{"ng1-1"}, readiness.Ready)
+	assert.Empty(t, readiness.Unready)
+	assert.Equal(t, []string{"ng1-1"}, readiness.DiskPressure)
+	assert.True(t, clusterstate.IsClusterHealthy())
+	assert.True(t, clusterstate.IsNodeUsableForScheduling(ng1_1, now))
+
+	// DiskPressure has now outlasted the grace period - the node is no longer counted Ready
+	// even though its NodeReady condition is still true, and the cluster is reported unhealthy.
+	err = clusterstate.UpdateNodes([]*apiv1.Node{ng1_1}, nil, now.Add(2*time.Minute))
+	assert.NoError(t, err)
+	readiness = clusterstate.GetClusterReadiness()
+	assert.Empty(t, readiness.Ready)
+	assert.Equal(t, []string{"ng1-1"}, readiness.Unready)
+	assert.Equal(t, []string{"ng1-1"}, readiness.DiskPressure)
+	assert.False(t, clusterstate.IsClusterHealthy())
+	assert.False(t, clusterstate.IsNodeUsableForScheduling(ng1_1, now.Add(2*time.Minute)))
+}
+
+func TestNodePressureConditionTransitions(t *testing.T) {
+	now := metav1.Time{Time: time.Now()}
+	later := metav1.Time{Time: now.Time.Add(10 * time.Second)}
+
+	oldStatus := &api.ClusterAutoscalerStatus{
+		NodeGroupStatuses: []api.NodeGroupStatus{
+			{
+				ProviderID: "ng1",
+				Conditions: []api.ClusterAutoscalerCondition{
+					{
+						Type:               api.ClusterAutoscalerNodePressure,
+						Status:             api.ClusterAutoscalerNoPressure,
+						LastProbeTime:      now,
+						LastTransitionTime: now,
+					},
+				},
+			},
+			{
+				ProviderID: "ng2",
+				Conditions: []api.ClusterAutoscalerCondition{
+					{
+						Type:               api.ClusterAutoscalerNodePressure,
+						Status:             api.ClusterAutoscalerPressureDetected,
+						Message:            "DiskPressure",
+						LastProbeTime:      now,
+						LastTransitionTime: now,
+					},
+				},
+			},
+		},
+	}
+
+	newStatus := &api.ClusterAutoscalerStatus{
+		NodeGroupStatuses: []api.NodeGroupStatus{
+			{
+				// ng1 developed pressure - its status changed, so the transition time moves.
+				ProviderID: "ng1",
+				Conditions: []api.ClusterAutoscalerCondition{
+					{Type: api.ClusterAutoscalerNodePressure, Status: api.ClusterAutoscalerPressureDetected, Message: "MemoryPressure", LastProbeTime: later},
+				},
+			},
+			{
+				// ng2's pressure is unchanged, just reported again with the same message.
+				ProviderID: "ng2",
+				Conditions: []api.ClusterAutoscalerCondition{
+					{Type: api.ClusterAutoscalerNodePressure, Status: api.ClusterAutoscalerPressureDetected, Message: "DiskPressure", LastProbeTime: later},
+				},
+			},
+		},
+	}
+
+	updateLastTransition(oldStatus, newStatus)
+
+	ng1Condition := api.GetConditionByType(api.ClusterAutoscalerNodePressure, newStatus.NodeGroupStatuses[0].Conditions)
+	assert.Equal(t, later, ng1Condition.LastTransitionTime)
+
+	ng2Condition := api.GetConditionByType(api.ClusterAutoscalerNodePressure, newStatus.NodeGroupStatuses[1].Conditions)
+	assert.Equal(t, now, ng2Condition.LastTransitionTime)
+}
+
 func newBackoff() backoff.Backoff {
 	return backoff.NewIdBasedExponentialBackoff(5*time.Minute, /*InitialNodeGroupBackoffDuration*/
 		30*time.Minute /*MaxNodeGroupBackoffDuration*/, 3*time.Hour /*NodeGroupBackoffResetTimeout*/)
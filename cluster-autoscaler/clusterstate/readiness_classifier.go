@@ -0,0 +1,136 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package clusterstate
+
+import (
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+)
+
+// NodeReadinessState is the verdict a NodeReadinessClassifier reaches for a single node.
+type NodeReadinessState string
+
+const (
+	// NodeReady means the classifier considers the node ready to run workloads.
+	NodeReady NodeReadinessState = "Ready"
+	// NodeNotStarted means the classifier considers the node still booting, e.g. waiting on a
+	// CNI plugin, a GPU driver DaemonSet or a storage driver to finish initializing.
+	NodeNotStarted NodeReadinessState = "NotStarted"
+	// NodeUnready means the classifier considers the node unready for reasons other than a
+	// still-pending boot sequence, e.g. it regressed after having been ready.
+	NodeUnready NodeReadinessState = "Unready"
+)
+
+// NodeReadinessClassifier decides whether a single node is Ready, NotStarted or Unready.
+// Operators with specialized boot sequences can register additional classifiers so their
+// nodes aren't counted as Ready - or don't trigger the MaxNodeProvisionTime timeout - while a
+// required condition specific to their environment is still pending.
+type NodeReadinessClassifier interface {
+	// Name identifies the classifier in the per-classifier readiness breakdown.
+	Name() string
+	// ClassifyNode returns this classifier's verdict for the given node, and whether the
+	// classifier has an opinion at all (applies). A classifier that doesn't apply to a node
+	// (e.g. one that only looks for a taint the node never had) is excluded from that node's
+	// breakdown entirely.
+	ClassifyNode(node *apiv1.Node, currentTime time.Time) (state NodeReadinessState, applies bool)
+}
+
+// defaultReadinessClassifier reproduces the autoscaler's original behavior: a node is Ready iff
+// its NodeReady condition is true and it doesn't carry the standard not-ready taint. A node that
+// is not Ready - whether it carries an explicit
+// Ready=false condition or hasn't reported a NodeReady condition at all yet - is NotStarted,
+// rather than Unready, while it still carries the standard not-ready taint, or while it is still
+// within maxNodeProvisionTime of its own creation (defaultMaxNodeProvisionTime if unset). Once
+// that grace period elapses, it falls through to Unready instead.
+type defaultReadinessClassifier struct {
+	maxNodeProvisionTime time.Duration
+}
+
+func (defaultReadinessClassifier) Name() string {
+	return "Default"
+}
+
+func (c defaultReadinessClassifier) ClassifyNode(node *apiv1.Node, currentTime time.Time) (NodeReadinessState, bool) {
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == apiv1.TaintNodeNotReady {
+			return NodeNotStarted, true
+		}
+	}
+
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == apiv1.NodeReady && condition.Status == apiv1.ConditionTrue {
+			return NodeReady, true
+		}
+	}
+
+	// The node isn't Ready - either it explicitly said so, or it hasn't reported a NodeReady
+	// condition yet at all. Give it up to maxNodeProvisionTime from its own creation to still
+	// come up before treating it as a regression rather than a still-booting node.
+	maxNodeProvisionTime := c.maxNodeProvisionTime
+	if maxNodeProvisionTime <= 0 {
+		maxNodeProvisionTime = defaultMaxNodeProvisionTime
+	}
+	age := currentTime.Sub(node.CreationTimestamp.Time)
+	if age >= 0 && age < maxNodeProvisionTime {
+		return NodeNotStarted, true
+	}
+	return NodeUnready, true
+}
+
+// CompositeClassifier combines a default classifier with any number of operator-supplied
+// NodeReadinessClassifiers and reduces their individual verdicts to a single state per node:
+// NotStarted if any classifier says NotStarted, else Unready if any says Unready, else Ready.
+type CompositeClassifier struct {
+	classifiers []NodeReadinessClassifier
+}
+
+// NewCompositeClassifier builds a CompositeClassifier running the default classifier - given
+// maxNodeProvisionTime as its NotStarted grace period - followed by the given extra classifiers,
+// in order.
+func NewCompositeClassifier(maxNodeProvisionTime time.Duration, extra ...NodeReadinessClassifier) *CompositeClassifier {
+	classifiers := make([]NodeReadinessClassifier, 0, len(extra)+1)
+	classifiers = append(classifiers, defaultReadinessClassifier{maxNodeProvisionTime: maxNodeProvisionTime})
+	classifiers = append(classifiers, extra...)
+	return &CompositeClassifier{classifiers: classifiers}
+}
+
+// Classify returns the combined readiness state of the node, along with the verdict of every
+// classifier that applied to it, keyed by classifier name, for the per-classifier breakdown
+// surfaced through GetClusterReadiness.
+func (c *CompositeClassifier) Classify(node *apiv1.Node, currentTime time.Time) (NodeReadinessState, map[string]NodeReadinessState) {
+	breakdown := make(map[string]NodeReadinessState)
+	overall := NodeReady
+
+	for _, classifier := range c.classifiers {
+		state, applies := classifier.ClassifyNode(node, currentTime)
+		if !applies {
+			continue
+		}
+		breakdown[classifier.Name()] = state
+
+		switch state {
+		case NodeNotStarted:
+			overall = NodeNotStarted
+		case NodeUnready:
+			if overall != NodeNotStarted {
+				overall = NodeUnready
+			}
+		}
+	}
+	return overall, breakdown
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package utils contains helpers for publishing cluster autoscaler status.
+package utils
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	kube_record "k8s.io/client-go/tools/record"
+)
+
+// LogRecorder records autoscaler events as Kubernetes events against the status ConfigMap.
+// It does not write the ConfigMap itself; writeStatusConfigMap is stored but not acted on.
+type LogRecorder struct {
+	kubeClient      kubernetes.Interface
+	recorder        kube_record.EventRecorder
+	statusConfigMap apiv1.ConfigMap
+	mapName         string
+	namespace       string
+	writeStatusConfigMap bool
+}
+
+// NewStatusMapRecorder creates a LogRecorder that publishes events against the ConfigMap
+// named mapName in the given namespace.
+func NewStatusMapRecorder(kubeClient kubernetes.Interface, namespace string, recorder kube_record.EventRecorder,
+	writeStatusConfigMap bool, mapName string) (*LogRecorder, error) {
+	return &LogRecorder{
+		kubeClient:            kubeClient,
+		recorder:              recorder,
+		statusConfigMap:       apiv1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: mapName, Namespace: namespace}},
+		mapName:               mapName,
+		namespace:             namespace,
+		writeStatusConfigMap:  writeStatusConfigMap,
+	}, nil
+}
+
+// Eventf records an event against the status ConfigMap, in the same way an event would be
+// recorded for any other Kubernetes object.
+func (lr *LogRecorder) Eventf(eventtype, reason, message string, args ...interface{}) {
+	if lr.recorder != nil {
+		lr.recorder.Eventf(&lr.statusConfigMap, eventtype, reason, message, args...)
+	}
+}
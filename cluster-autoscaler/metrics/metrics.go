@@ -0,0 +1,43 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package metrics holds the hooks the autoscaler calls to report metrics. The hooks record
+// nothing; no Prometheus metric is exported from this package.
+package metrics
+
+// FailedScaleUpReason is the reason why a scale-up failed, used for metrics and status reporting.
+type FailedScaleUpReason string
+
+const (
+	// Timeout means the scale-up failed because the expected nodes did not show up in time.
+	Timeout FailedScaleUpReason = "timeout"
+	// APIError means the scale-up failed because of an error returned by the cloud provider API.
+	APIError FailedScaleUpReason = "apiError"
+	// CloudProviderError is an alias of APIError kept for readability at call sites that talk
+	// specifically about cloud provider failures rather than generic API errors.
+	CloudProviderError FailedScaleUpReason = "apiError"
+	// QuotaExceeded means the scale-up failed because it would have exceeded a cloud provider quota.
+	QuotaExceeded FailedScaleUpReason = "quotaExceeded"
+)
+
+// RegisterFailedScaleUp is called for every failed scale-up with its reason. It does nothing.
+func RegisterFailedScaleUp(reason FailedScaleUpReason) {
+}
+
+// RegisterOrphanedNode is called with the orphan state (KubernetesOnly, CloudOnly or Detached)
+// of a node/instance whose reconciliation grace period has elapsed. It does nothing.
+func RegisterOrphanedNode(state string) {
+}
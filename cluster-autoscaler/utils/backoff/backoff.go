@@ -0,0 +1,119 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package backoff tracks exponential backoff for failing node groups so that the
+// autoscaler does not retry a broken node group on every loop.
+package backoff
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/autoscaler/cluster-autoscaler/cloudprovider"
+)
+
+// Backoff allows time-based backing off of node groups considered unhealthy.
+type Backoff interface {
+	// Backoff execution for the given node group and return the time when the execution
+	// should be retried.
+	Backoff(nodeGroup cloudprovider.NodeGroup, nodeInfo interface{}, currentTime time.Time) time.Time
+	// IsBackedOff returns true if execution is currently backed off for the given node group.
+	IsBackedOff(nodeGroup cloudprovider.NodeGroup, nodeInfo interface{}, currentTime time.Time) bool
+	// RemoveBackoff removes backoff data for the given node group.
+	RemoveBackoff(nodeGroup cloudprovider.NodeGroup, nodeInfo interface{})
+	// RemoveStaleBackoffData removes stale backoff data that is no longer relevant.
+	RemoveStaleBackoffData(currentTime time.Time)
+}
+
+type idNodeGroupBackoffInfo struct {
+	duration          time.Duration
+	backoffUntil      time.Time
+	lastFailedExecution time.Time
+}
+
+// IdBasedExponentialBackoff is an implementation of the Backoff interface keying backoff state
+// on the node group id with exponentially increasing backoff duration.
+type IdBasedExponentialBackoff struct {
+	sync.Mutex
+	initialBackoffDuration time.Duration
+	maxBackoffDuration     time.Duration
+	resetTimeout           time.Duration
+	backoffInfo            map[string]idNodeGroupBackoffInfo
+}
+
+// NewIdBasedExponentialBackoff creates an instance of exponential backoff backing off based on
+// node group id.
+func NewIdBasedExponentialBackoff(initialBackoffDuration time.Duration, maxBackoffDuration time.Duration,
+	resetTimeout time.Duration) Backoff {
+	return &IdBasedExponentialBackoff{
+		initialBackoffDuration: initialBackoffDuration,
+		maxBackoffDuration:     maxBackoffDuration,
+		resetTimeout:           resetTimeout,
+		backoffInfo:            make(map[string]idNodeGroupBackoffInfo),
+	}
+}
+
+// Backoff execution for the given node group, return the time when the execution should be retried.
+func (b *IdBasedExponentialBackoff) Backoff(nodeGroup cloudprovider.NodeGroup, nodeInfo interface{}, currentTime time.Time) time.Time {
+	b.Lock()
+	defer b.Unlock()
+
+	id := nodeGroup.Id()
+	info, found := b.backoffInfo[id]
+	duration := b.initialBackoffDuration
+	if found && currentTime.Sub(info.lastFailedExecution) < b.resetTimeout {
+		duration = info.duration * 2
+		if duration > b.maxBackoffDuration {
+			duration = b.maxBackoffDuration
+		}
+	}
+	info = idNodeGroupBackoffInfo{
+		duration:            duration,
+		backoffUntil:        currentTime.Add(duration),
+		lastFailedExecution: currentTime,
+	}
+	b.backoffInfo[id] = info
+	return info.backoffUntil
+}
+
+// IsBackedOff returns true if execution is currently backed off for the given node group.
+func (b *IdBasedExponentialBackoff) IsBackedOff(nodeGroup cloudprovider.NodeGroup, nodeInfo interface{}, currentTime time.Time) bool {
+	b.Lock()
+	defer b.Unlock()
+
+	info, found := b.backoffInfo[nodeGroup.Id()]
+	return found && info.backoffUntil.After(currentTime)
+}
+
+// RemoveBackoff removes backoff data for the given node group.
+func (b *IdBasedExponentialBackoff) RemoveBackoff(nodeGroup cloudprovider.NodeGroup, nodeInfo interface{}) {
+	b.Lock()
+	defer b.Unlock()
+
+	delete(b.backoffInfo, nodeGroup.Id())
+}
+
+// RemoveStaleBackoffData removes stale backoff data that is no longer relevant.
+func (b *IdBasedExponentialBackoff) RemoveStaleBackoffData(currentTime time.Time) {
+	b.Lock()
+	defer b.Unlock()
+
+	for id, info := range b.backoffInfo {
+		if currentTime.Sub(info.lastFailedExecution) > b.resetTimeout {
+			delete(b.backoffInfo, id)
+		}
+	}
+}
@@ -0,0 +1,38 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package deletetaint handles tainting nodes that are about to be deleted by the autoscaler.
+package deletetaint
+
+import (
+	apiv1 "k8s.io/api/core/v1"
+)
+
+const (
+	// ToBeDeletedTaint is a taint used to make the node unschedulable while it is being
+	// drained and deleted by the autoscaler.
+	ToBeDeletedTaint = "ToBeDeletedByClusterAutoscaler"
+)
+
+// HasToBeDeletedTaint returns true if the node has the ToBeDeletedTaint.
+func HasToBeDeletedTaint(node *apiv1.Node) bool {
+	for _, taint := range node.Spec.Taints {
+		if taint.Key == ToBeDeletedTaint {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2016 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package test contains helpers shared by unit tests across the autoscaler.
+package test
+
+import (
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// BuildTestNode creates a node with specified capacity.
+func BuildTestNode(name string, millicpu int64, mem int64) *apiv1.Node {
+	node := &apiv1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:     name,
+			SelfLink: "/api/v1/nodes/" + name,
+		},
+		Spec: apiv1.NodeSpec{},
+		Status: apiv1.NodeStatus{
+			Capacity: apiv1.ResourceList{
+				apiv1.ResourcePods: *resource.NewQuantity(100, resource.DecimalSI),
+			},
+		},
+	}
+
+	if millicpu >= 0 {
+		node.Status.Capacity[apiv1.ResourceCPU] = *resource.NewMilliQuantity(millicpu, resource.DecimalSI)
+	}
+	if mem >= 0 {
+		node.Status.Capacity[apiv1.ResourceMemory] = *resource.NewQuantity(mem, resource.DecimalSI)
+	}
+
+	node.Status.Allocatable = apiv1.ResourceList{}
+	for k, v := range node.Status.Capacity {
+		node.Status.Allocatable[k] = v
+	}
+
+	return node
+}
+
+// SetNodeReadyState sets node ready state to either ready or unready.
+func SetNodeReadyState(node *apiv1.Node, ready bool, transitionTime time.Time) {
+	for i := range node.Status.Conditions {
+		if node.Status.Conditions[i].Type == apiv1.NodeReady {
+			node.Status.Conditions[i].LastTransitionTime = metav1.Time{Time: transitionTime}
+			if ready {
+				node.Status.Conditions[i].Status = apiv1.ConditionTrue
+				node.Status.Conditions[i].Reason = "KubeletReady"
+				node.Status.Conditions[i].Message = "kubelet is posting ready status"
+			} else {
+				node.Status.Conditions[i].Status = apiv1.ConditionFalse
+				node.Status.Conditions[i].Reason = "KubeletNotReady"
+				node.Status.Conditions[i].Message = "kubelet is not posting ready status"
+			}
+			return
+		}
+	}
+	condition := apiv1.NodeCondition{
+		Type:               apiv1.NodeReady,
+		LastTransitionTime: metav1.Time{Time: transitionTime},
+	}
+	if ready {
+		condition.Status = apiv1.ConditionTrue
+		condition.Reason = "KubeletReady"
+		condition.Message = "kubelet is posting ready status"
+	} else {
+		condition.Status = apiv1.ConditionFalse
+		condition.Reason = "KubeletNotReady"
+		condition.Message = "kubelet is not posting ready status"
+	}
+	node.Status.Conditions = append(node.Status.Conditions, condition)
+}
+
+// SetNodeNotReadyTaint sets the standard node.kubernetes.io/not-ready taint on the given node.
+func SetNodeNotReadyTaint(node *apiv1.Node) {
+	node.Spec.Taints = append(node.Spec.Taints, apiv1.Taint{
+		Key:    apiv1.TaintNodeNotReady,
+		Effect: apiv1.TaintEffectNoSchedule,
+	})
+}
+
+// RemoveNodeNotReadyTaint removes the node.kubernetes.io/not-ready taint from the given node.
+func RemoveNodeNotReadyTaint(node *apiv1.Node) {
+	var newTaints []apiv1.Taint
+	for _, taint := range node.Spec.Taints {
+		if taint.Key != apiv1.TaintNodeNotReady {
+			newTaints = append(newTaints, taint)
+		}
+	}
+	node.Spec.Taints = newTaints
+}
+
+// SetNodeCondition sets, or replaces, the given condition type on the node with the given
+// status, using transitionTime as its LastTransitionTime.
+func SetNodeCondition(node *apiv1.Node, conditionType apiv1.NodeConditionType, status apiv1.ConditionStatus, transitionTime time.Time) {
+	for i := range node.Status.Conditions {
+		if node.Status.Conditions[i].Type == conditionType {
+			node.Status.Conditions[i].Status = status
+			node.Status.Conditions[i].LastTransitionTime = metav1.Time{Time: transitionTime}
+			return
+		}
+	}
+	node.Status.Conditions = append(node.Status.Conditions, apiv1.NodeCondition{
+		Type:               conditionType,
+		Status:             status,
+		LastTransitionTime: metav1.Time{Time: transitionTime},
+	})
+}